@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/common/util"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"github.com/barreleye-labs/barreleye/keystore"
+	"golang.org/x/term"
+	"os"
+	"strings"
+)
+
+const accountUsage = `usage: barreleye account <command> [flags]
+
+commands:
+  new                create a key and store it encrypted in the keystore
+  import <keyfile>   encrypt the hex private key in keyfile into the keystore
+  export <address>   print the hex private key of an address in the keystore
+  list               list the addresses in the keystore`
+
+// runAccountCommand runs the account commands that manage the encrypted keystore.
+func runAccountCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New(accountUsage)
+	}
+
+	fs := flag.NewFlagSet("account "+args[0], flag.ExitOnError)
+	dir := fs.String("keystore", "keys", "keystore directory")
+	passwordFile := fs.String("password", "", "file with the keystore password, prompted for if empty")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "new":
+		password, err := readPassword(*passwordFile, true)
+		if err != nil {
+			return err
+		}
+		return storeKey(*dir, types.GeneratePrivateKey(), password)
+
+	case "import":
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: barreleye account import [flags] <keyfile>")
+		}
+
+		hexKey, err := os.ReadFile(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		key, err := parseHexKey(string(hexKey))
+		if err != nil {
+			return err
+		}
+
+		password, err := readPassword(*passwordFile, true)
+		if err != nil {
+			return err
+		}
+		return storeKey(*dir, key, password)
+
+	case "export":
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: barreleye account export [flags] <address>")
+		}
+
+		address, err := common.ParseAddress(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		account, err := keystore.Find(*dir, address)
+		if err != nil {
+			return err
+		}
+
+		key, err := loadKey(account.Path, *passwordFile)
+		if err != nil {
+			return err
+		}
+		fmt.Println(hex.EncodeToString(key.Bytes()))
+		return nil
+
+	case "list":
+		accounts, err := keystore.List(*dir)
+		if err != nil {
+			return err
+		}
+
+		for _, account := range accounts {
+			fmt.Printf("%s %s\n", account.Address.Bech32(), account.Path)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown account command %s\n\n%s", args[0], accountUsage)
+}
+
+// loadKey decrypts a key file with the password in passwordFile, or with a password
+// read from the terminal if passwordFile is empty.
+func loadKey(path string, passwordFile string) (*types.PrivateKey, error) {
+	password, err := readPassword(passwordFile, false)
+	if err != nil {
+		return nil, err
+	}
+	return keystore.Load(path, password)
+}
+
+func storeKey(dir string, key *types.PrivateKey, password string) error {
+	path, err := keystore.Store(dir, key, password, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("address: %s\nkey file: %s\n", key.PublicKey.Address().Bech32(), path)
+	return nil
+}
+
+func parseHexKey(s string) (*types.PrivateKey, error) {
+	b, err := hex.DecodeString(util.Rm0x(strings.TrimSpace(s)))
+	if err != nil {
+		return nil, fmt.Errorf("key file must hold a hex private key")
+	}
+	return types.PrivateKeyFromBytes(b)
+}
+
+// readPassword reads the first line of passwordFile, or prompts for the password on the
+// terminal without echoing it. A new password is prompted for twice.
+func readPassword(passwordFile string, confirm bool) (string, error) {
+	if passwordFile != "" {
+		return readFirstLine(passwordFile)
+	}
+
+	password, err := promptSecret("Password: ")
+	if err != nil {
+		return "", err
+	}
+
+	if confirm {
+		repeated, err := promptSecret("Repeat password: ")
+		if err != nil {
+			return "", err
+		}
+
+		if repeated != password {
+			return "", fmt.Errorf("passwords do not match")
+		}
+	}
+	return password, nil
+}
+
+// promptSecret reads a line from the terminal without echoing it.
+func promptSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("no terminal to prompt for secrets, read them from files instead")
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	secret, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+func readFirstLine(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(strings.SplitN(string(b), "\n", 2)[0], "\r"), nil
+}
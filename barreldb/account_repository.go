@@ -2,6 +2,7 @@ package barreldb
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"github.com/barreleye-labs/barreleye/common"
 	"github.com/barreleye-labs/barreleye/core/types"
@@ -45,7 +46,7 @@ func (barrelDB *BarrelDatabase) SelectAddressAccount(address common.Address) (*t
 	return account, nil
 }
 
-func (barrelDB *BarrelDatabase) SelectAccountBalance(address common.Address) (*uint64, error) {
+func (barrelDB *BarrelDatabase) SelectAccountBalance(address common.Address) (*common.Amount, error) {
 	data, err := barrelDB.GetTable(AddressAccountTableName).Get(address.ToSlice())
 	if err != nil {
 		if err.Error() != common.LevelDBNotFoundError {
@@ -63,7 +64,7 @@ func (barrelDB *BarrelDatabase) SelectAccountBalance(address common.Address) (*u
 	return &account.Balance, nil
 }
 
-func (barrelDB *BarrelDatabase) IncreaseAccountBalance(address common.Address, amount uint64) error {
+func (barrelDB *BarrelDatabase) IncreaseAccountBalance(address common.Address, amount common.Amount) error {
 	account, err := barrelDB.SelectAddressAccount(address)
 	if err != nil {
 		return err
@@ -73,14 +74,16 @@ func (barrelDB *BarrelDatabase) IncreaseAccountBalance(address common.Address, a
 		return fmt.Errorf("not found account")
 	}
 
-	account.Balance += amount
+	if err = account.AddBalance(amount); err != nil {
+		return err
+	}
 	if err = barrelDB.UpsertAddressAccount(account.Address, account); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (barrelDB *BarrelDatabase) DecreaseAccountBalance(address common.Address, amount uint64) error {
+func (barrelDB *BarrelDatabase) DecreaseAccountBalance(address common.Address, amount common.Amount) error {
 	account, err := barrelDB.SelectAddressAccount(address)
 	if err != nil {
 		return err
@@ -90,13 +93,67 @@ func (barrelDB *BarrelDatabase) DecreaseAccountBalance(address common.Address, a
 		return fmt.Errorf("not found account")
 	}
 
-	if account.Balance < amount {
+	if account.Balance.Lt(amount) {
 		return fmt.Errorf("not enough balance")
 	}
 
-	account.Balance -= amount
+	if err = account.SubBalance(amount); err != nil {
+		return err
+	}
 	if err = barrelDB.UpsertAddressAccount(account.Address, account); err != nil {
 		return err
 	}
 	return nil
 }
+
+// AddressHeightAccount Repository
+// The account an address had after the block of a height is stored under the address
+// followed by the big endian height, so the keys of an address are sorted by height.
+// An empty value means that the account did not exist after the block.
+func addressHeightKey(address common.Address, height int32) []byte {
+	return binary.BigEndian.AppendUint32(address.ToSlice(), uint32(height))
+}
+
+func (barrelDB *BarrelDatabase) InsertAddressHeightAccount(address common.Address, height int32, account *types.Account) error {
+	buf := &bytes.Buffer{}
+	if account != nil {
+		if err := account.Encode(types.NewGobAccountEncoder(buf)); err != nil {
+			return err
+		}
+	}
+
+	if err := barrelDB.GetTable(AddressHeightAccountTableName).Put(addressHeightKey(address, height), buf.Bytes()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (barrelDB *BarrelDatabase) DeleteAddressHeightAccount(address common.Address, height int32) error {
+	if err := barrelDB.GetTable(AddressHeightAccountTableName).Delete(addressHeightKey(address, height)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (barrelDB *BarrelDatabase) HasAddressHeightAccount(address common.Address, height int32) (bool, error) {
+	return barrelDB.GetTable(AddressHeightAccountTableName).Has(addressHeightKey(address, height))
+}
+
+// SelectAddressAccountAtHeight returns the account the address had after the block of
+// the height, or nil if it did not exist yet.
+func (barrelDB *BarrelDatabase) SelectAddressAccountAtHeight(address common.Address, height int32) (*types.Account, error) {
+	_, data, err := barrelDB.GetTable(AddressHeightAccountTableName).LastBefore(address.ToSlice(), addressHeightKey(address, height+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	account := new(types.Account)
+	if err = account.Decode(types.NewGobAccountDecoder(bytes.NewBuffer(data))); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
@@ -46,13 +46,11 @@ func (barrelDB *BarrelDatabase) SelectHashBlock(hash common.Hash) (*types.Block,
 }
 
 // HeightBlock Repository
+// The height index stores the hash of the block, the block itself is stored once in the
+// hash index. Databases written before may still hold whole blocks in the height index.
 func (barrelDB *BarrelDatabase) InsertHeightBlock(height int32, block *types.Block) error {
-	buf := &bytes.Buffer{}
-	if err := block.Encode(types.NewGobBlockEncoder(buf)); err != nil {
-		return err
-	}
-
-	if err := barrelDB.GetTable(HeightBlockTableName).Put([]byte(strconv.Itoa(int(height))), buf.Bytes()); err != nil {
+	hash := block.GetHash()
+	if err := barrelDB.GetTable(HeightBlockTableName).Put([]byte(strconv.Itoa(int(height))), hash.ToSlice()); err != nil {
 		return err
 	}
 	return nil
@@ -65,6 +63,34 @@ func (barrelDB *BarrelDatabase) DeleteHeightBlock(height int32) error {
 	return nil
 }
 
+// SelectHeightBlockHash returns the hash of the block of the height, even if the block
+// itself has been pruned.
+func (barrelDB *BarrelDatabase) SelectHeightBlockHash(height int32) (*common.Hash, error) {
+	data, err := barrelDB.GetTable(HeightBlockTableName).Get([]byte(strconv.Itoa(int(height))))
+	if err != nil {
+		if err.Error() != common.LevelDBNotFoundError {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	if len(data) == common.HashLength {
+		hash, err := common.HashFromBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		return &hash, nil
+	}
+
+	block := new(types.Block)
+	if err = block.Decode(types.NewGobBlockDecoder(bytes.NewBuffer(data))); err != nil {
+		return nil, err
+	}
+
+	hash := block.GetHash()
+	return &hash, nil
+}
+
 func (barrelDB *BarrelDatabase) SelectHeightBlock(height int32) (*types.Block, error) {
 	data, err := barrelDB.GetTable(HeightBlockTableName).Get([]byte(strconv.Itoa(int(height))))
 	if err != nil {
@@ -74,6 +100,14 @@ func (barrelDB *BarrelDatabase) SelectHeightBlock(height int32) (*types.Block, e
 		return nil, nil
 	}
 
+	if len(data) == common.HashLength {
+		hash, err := common.HashFromBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		return barrelDB.SelectHashBlock(hash)
+	}
+
 	block := new(types.Block)
 	err = block.Decode(types.NewGobBlockDecoder(bytes.NewBuffer(data)))
 	if err != nil {
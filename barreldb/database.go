@@ -1,9 +1,14 @@
 package barreldb
 
 import (
+	"errors"
 	"flag"
+	"fmt"
 	"github.com/barreleye-labs/barreleye/common"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+	"github.com/syndtr/goleveldb/leveldb/util"
 	"os"
 	"os/user"
 	"path"
@@ -11,9 +16,16 @@ import (
 	"runtime"
 )
 
+// ErrStopIteration ends IterateRange early when it is returned by the callback.
+var ErrStopIteration = errors.New("stop iteration")
+
 type BarrelDatabase struct {
 	db     *leveldb.DB
 	tables map[string]*Table
+
+	// batch and pending are only set on databases created by NewBatch.
+	batch   *leveldb.Batch
+	pending map[string][]byte // key => value, nil if deleted
 }
 
 func New() (*BarrelDatabase, error) {
@@ -24,6 +36,15 @@ func New() (*BarrelDatabase, error) {
 	return &BarrelDatabase{db: db, tables: make(map[string]*Table)}, nil
 }
 
+// NewMemory opens a database that is kept in memory and lost when it is closed.
+func NewMemory() (*BarrelDatabase, error) {
+	db, err := leveldb.Open(storage.NewMemStorage(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return &BarrelDatabase{db: db, tables: make(map[string]*Table)}, nil
+}
+
 func (barrelDB *BarrelDatabase) Close() error {
 	err := barrelDB.db.Close()
 	return err
@@ -39,23 +60,130 @@ func (barrelDB *BarrelDatabase) GetTable(name string) *Table {
 	return barrelDB.tables[name]
 }
 
+// NewBatch returns a database with the same tables whose writes are buffered in
+// memory until Commit is called, so that they reach the disk atomically. Reads on
+// the batch see the buffered writes.
+func (barrelDB *BarrelDatabase) NewBatch() *BarrelDatabase {
+	batch := &BarrelDatabase{
+		db:      barrelDB.db,
+		tables:  make(map[string]*Table),
+		batch:   new(leveldb.Batch),
+		pending: make(map[string][]byte),
+	}
+
+	for name, table := range barrelDB.tables {
+		batch.tables[name] = NewTable(batch, table.Prefix)
+	}
+	return batch
+}
+
+// Commit atomically writes the buffered writes of a batch to the disk.
+func (barrelDB *BarrelDatabase) Commit() error {
+	if barrelDB.batch == nil {
+		return fmt.Errorf("database is not a batch")
+	}
+
+	if err := barrelDB.db.Write(barrelDB.batch, &opt.WriteOptions{Sync: true}); err != nil {
+		return err
+	}
+
+	barrelDB.batch.Reset()
+	barrelDB.pending = make(map[string][]byte)
+	return nil
+}
+
 func (barrelDB *BarrelDatabase) Get(key []byte) ([]byte, error) {
+	if value, ok := barrelDB.pending[string(key)]; ok {
+		if value == nil {
+			return nil, leveldb.ErrNotFound
+		}
+		return value, nil
+	}
 
 	return barrelDB.db.Get(key, nil)
 }
 
 func (barrelDB *BarrelDatabase) Has(key []byte) (bool, error) {
+	if value, ok := barrelDB.pending[string(key)]; ok {
+		return value != nil, nil
+	}
+
 	return barrelDB.db.Has(key, nil)
 }
 
 func (barrelDB *BarrelDatabase) Put(key []byte, value []byte) error {
+	if barrelDB.batch != nil {
+		barrelDB.batch.Put(key, value)
+		barrelDB.pending[string(key)] = append([]byte{}, value...)
+		return nil
+	}
+
 	return barrelDB.db.Put(key, value, nil)
 }
 
 func (barrelDB *BarrelDatabase) Delete(key []byte) error {
+	if barrelDB.batch != nil {
+		barrelDB.batch.Delete(key)
+		barrelDB.pending[string(key)] = nil
+		return nil
+	}
+
 	return barrelDB.db.Delete(key, nil)
 }
 
+// Iterate calls fn for every key-value pair whose key starts with prefix, in key order.
+// The slices passed to fn are only valid until fn returns. Writes buffered in a batch
+// are not visible to Iterate.
+func (barrelDB *BarrelDatabase) Iterate(prefix []byte, fn func(key []byte, value []byte) error) error {
+	iter := barrelDB.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		if err := fn(iter.Key(), iter.Value()); err != nil {
+			return err
+		}
+	}
+	return iter.Error()
+}
+
+// IterateRange calls fn for every key-value pair with start <= key < limit, in key order.
+// Iteration stops without an error when fn returns ErrStopIteration. Writes buffered
+// in a batch are not visible to IterateRange.
+func (barrelDB *BarrelDatabase) IterateRange(start []byte, limit []byte, fn func(key []byte, value []byte) error) error {
+	iter := barrelDB.db.NewIterator(&util.Range{Start: start, Limit: limit}, nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		if err := fn(iter.Key(), iter.Value()); err != nil {
+			if errors.Is(err, ErrStopIteration) {
+				break
+			}
+			return err
+		}
+	}
+	return iter.Error()
+}
+
+// LastBefore returns the last key-value pair whose key starts with prefix and is below
+// limit, or nil slices if there is none. It seeks to limit and steps back one entry
+// instead of iterating the keys before it. Writes buffered in a batch are not visible.
+func (barrelDB *BarrelDatabase) LastBefore(prefix []byte, limit []byte) ([]byte, []byte, error) {
+	iter := barrelDB.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+
+	var found bool
+	if iter.Seek(limit) {
+		found = iter.Prev()
+	} else {
+		found = iter.Last()
+	}
+
+	if !found {
+		return nil, nil, iter.Error()
+	}
+	return append([]byte{}, iter.Key()...), append([]byte{}, iter.Value()...), iter.Error()
+}
+
 func DefaultDataDir() string {
 	_, filename, _, _ := runtime.Caller(0)
 	pwd := path.Dir(filename)
@@ -1,7 +1,65 @@
 package barreldb
 
-import "testing"
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
 
 func TestPut(t *testing.T) {
 
 }
+
+func newMemoryDatabase(t *testing.T) *BarrelDatabase {
+	barrelDB, err := NewMemory()
+	assert.Nil(t, err)
+
+	assert.Nil(t, barrelDB.CreateTable(LastTxNumberTableName, LastTxNumberPrefix))
+	return barrelDB
+}
+
+func TestBatch(t *testing.T) {
+	barrelDB := newMemoryDatabase(t)
+	defer barrelDB.Close()
+
+	assert.Nil(t, barrelDB.UpsertLastTxNumber(1))
+
+	batch := barrelDB.NewBatch()
+	assert.Nil(t, batch.UpsertLastTxNumber(2))
+
+	number, err := batch.SelectLastTxNumber()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(2), *number)
+
+	number, err = barrelDB.SelectLastTxNumber()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), *number)
+
+	assert.Nil(t, batch.DeleteLastTxNumber())
+	number, err = batch.SelectLastTxNumber()
+	assert.Nil(t, err)
+	assert.Nil(t, number)
+
+	assert.Nil(t, batch.Commit())
+	number, err = barrelDB.SelectLastTxNumber()
+	assert.Nil(t, err)
+	assert.Nil(t, number)
+
+	assert.NotNil(t, barrelDB.Commit())
+}
+
+func TestLastBefore(t *testing.T) {
+	barrelDB := newMemoryDatabase(t)
+	defer barrelDB.Close()
+
+	table := barrelDB.GetTable(LastTxNumberTableName)
+	for _, key := range []string{"a1", "a3", "b0"} {
+		assert.Nil(t, table.Put([]byte(key), []byte(key)))
+	}
+
+	for limit, expected := range map[string]string{"a0": "", "a2": "a1", "a3": "a1", "a4": "a3", "a9": "a3"} {
+		key, value, err := table.LastBefore([]byte("a"), []byte(limit))
+		assert.Nil(t, err)
+		assert.Equal(t, expected, string(key), limit)
+		assert.Equal(t, expected, string(value), limit)
+	}
+}
@@ -0,0 +1,45 @@
+package barreldb
+
+import (
+	"bytes"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/core/types"
+)
+
+// HashReceipt Repository
+func (barrelDB *BarrelDatabase) InsertHashReceipt(hash common.Hash, receipt *types.Receipt) error {
+	buf := &bytes.Buffer{}
+	if err := receipt.Encode(types.NewGobReceiptEncoder(buf)); err != nil {
+		return err
+	}
+
+	if err := barrelDB.GetTable(HashReceiptTableName).Put(hash.ToSlice(), buf.Bytes()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (barrelDB *BarrelDatabase) DeleteHashReceipt(hash common.Hash) error {
+	if err := barrelDB.GetTable(HashReceiptTableName).Delete(hash.ToSlice()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (barrelDB *BarrelDatabase) SelectHashReceipt(hash common.Hash) (*types.Receipt, error) {
+	data, err := barrelDB.GetTable(HashReceiptTableName).Get(hash.ToSlice())
+	if err != nil {
+		if err.Error() != common.LevelDBNotFoundError {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	receipt := new(types.Receipt)
+	err = receipt.Decode(types.NewGobReceiptDecoder(bytes.NewBuffer(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
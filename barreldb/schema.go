@@ -15,7 +15,31 @@ var (
 	LastTxTableName       = "lastTx"
 	LastTxNumberTableName = "lastTxNumber"
 
-	AddressAccountTableName = "address-account"
+	HashReceiptTableName = "hash-receipt"
+
+	AddressAccountTableName       = "address-account"
+	AddressHeightAccountTableName = "address-height-account"
+	ChainSupplyTableName          = "chain-supply"
+
+	SymbolTokenTableName         = "symbol-token"
+	AddressTokenBalanceTableName = "address-token-balance"
+
+	AddressMultisigTableName = "address-multisig"
+
+	HashLockTableName   = "hash-lock"
+	HeightLockTableName = "height-lock"
+	TimeLockTableName   = "time-lock"
+
+	AddressCodeTableName    = "address-code"
+	AddressStorageTableName = "address-storage"
+
+	StateTrieTableName = "state-trie"
+
+	HeightUndoTableName = "height-undo"
+
+	SnapshotTableName      = "snapshot"
+	SnapshotStateTableName = "state-snapshot"
+	PruneMarkTableName     = "pruneMark"
 
 	// prefix ----------------------------------------
 	HashBlockPrefix   = "hash-block"
@@ -31,5 +55,29 @@ var (
 	LastTxPrefix       = "lastTx"
 	LastTxNumberPrefix = "lastTxNumber"
 
-	AddressAccountPrefix = "address-account"
+	HashReceiptPrefix = "hash-receipt"
+
+	AddressAccountPrefix       = "address-account"
+	AddressHeightAccountPrefix = "address-height-account"
+	ChainSupplyPrefix          = "chain-supply"
+
+	SymbolTokenPrefix         = "symbol-token"
+	AddressTokenBalancePrefix = "address-token-balance"
+
+	AddressMultisigPrefix = "address-multisig"
+
+	HashLockPrefix   = "hash-lock"
+	HeightLockPrefix = "height-lock"
+	TimeLockPrefix   = "time-lock"
+
+	AddressCodePrefix    = "address-code"
+	AddressStoragePrefix = "address-storage"
+
+	StateTriePrefix = "state-trie"
+
+	HeightUndoPrefix = "height-undo"
+
+	SnapshotPrefix      = "snapshot"
+	SnapshotStatePrefix = "state-snapshot"
+	PruneMarkPrefix     = "pruneMark"
 )
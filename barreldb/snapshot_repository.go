@@ -0,0 +1,118 @@
+package barreldb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"github.com/barreleye-labs/barreleye/common"
+)
+
+// Snapshot is a copy of the state tables taken after the block of Height.
+// TxCount is the number of transactions in the blocks up to Height.
+type Snapshot struct {
+	Height  int32
+	TxCount uint32
+}
+
+// PruneMark tells which blocks have been pruned. The blocks below Height have no body,
+// transactions, receipts or undo record left. TxCount is the number of transactions in them.
+type PruneMark struct {
+	Height  int32
+	TxCount uint32
+}
+
+func heightKey(height int32) []byte {
+	return binary.BigEndian.AppendUint32(nil, uint32(height))
+}
+
+// snapshotStateKey is the height followed by the state table name and the key in the table.
+func snapshotStateKey(height int32, table string, key []byte) []byte {
+	k := append(heightKey(height), table...)
+	return append(append(k, 0), key...)
+}
+
+// Snapshot Repository
+func (barrelDB *BarrelDatabase) InsertSnapshot(snapshot *Snapshot) error {
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(snapshot); err != nil {
+		return err
+	}
+
+	if err := barrelDB.GetTable(SnapshotTableName).Put(heightKey(snapshot.Height), buf.Bytes()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (barrelDB *BarrelDatabase) InsertSnapshotState(height int32, table string, key []byte, value []byte) error {
+	if err := barrelDB.GetTable(SnapshotStateTableName).Put(snapshotStateKey(height, table, key), value); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteSnapshot deletes the snapshot of the height with all of its state.
+func (barrelDB *BarrelDatabase) DeleteSnapshot(height int32) error {
+	if err := barrelDB.GetTable(SnapshotTableName).Delete(heightKey(height)); err != nil {
+		return err
+	}
+
+	return barrelDB.GetTable(SnapshotStateTableName).IteratePrefix(heightKey(height), func(key []byte, value []byte) error {
+		return barrelDB.GetTable(SnapshotStateTableName).Delete(append([]byte{}, key...))
+	})
+}
+
+// SelectSnapshots returns all snapshots ordered by height.
+func (barrelDB *BarrelDatabase) SelectSnapshots() ([]*Snapshot, error) {
+	snapshots := []*Snapshot{}
+	err := barrelDB.GetTable(SnapshotTableName).Iterate(func(key []byte, value []byte) error {
+		snapshot := new(Snapshot)
+		if err := gob.NewDecoder(bytes.NewBuffer(value)).Decode(snapshot); err != nil {
+			return err
+		}
+		snapshots = append(snapshots, snapshot)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// IterateSnapshotState calls fn for every key of the state tables in the snapshot of the height.
+func (barrelDB *BarrelDatabase) IterateSnapshotState(height int32, fn func(table string, key []byte, value []byte) error) error {
+	return barrelDB.GetTable(SnapshotStateTableName).IteratePrefix(heightKey(height), func(key []byte, value []byte) error {
+		rest := key[4:]
+		i := bytes.IndexByte(rest, 0)
+		return fn(string(rest[:i]), rest[i+1:], value)
+	})
+}
+
+// PruneMark Repository
+func (barrelDB *BarrelDatabase) UpsertPruneMark(mark *PruneMark) error {
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(mark); err != nil {
+		return err
+	}
+
+	if err := barrelDB.GetTable(PruneMarkTableName).Put([]byte{}, buf.Bytes()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (barrelDB *BarrelDatabase) SelectPruneMark() (*PruneMark, error) {
+	data, err := barrelDB.GetTable(PruneMarkTableName).Get([]byte{})
+	if err != nil {
+		if err.Error() != common.LevelDBNotFoundError {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	mark := new(PruneMark)
+	if err = gob.NewDecoder(bytes.NewBuffer(data)).Decode(mark); err != nil {
+		return nil, err
+	}
+	return mark, nil
+}
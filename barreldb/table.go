@@ -1,5 +1,7 @@
 package barreldb
 
+import "github.com/syndtr/goleveldb/leveldb/util"
+
 type Table struct {
 	DB     *BarrelDatabase
 	Prefix string
@@ -30,3 +32,38 @@ func (t *Table) Put(key []byte, value []byte) error {
 func (t *Table) Delete(key []byte) error {
 	return t.DB.Delete(append([]byte(t.Prefix), key...))
 }
+
+// Iterate calls fn for every key in the table with the prefix stripped from the key.
+func (t *Table) Iterate(fn func(key []byte, value []byte) error) error {
+	return t.IteratePrefix(nil, fn)
+}
+
+// IterateRange calls fn for every key in the table with start <= key < limit, with the
+// prefix stripped from the key. A nil limit iterates to the end of the table.
+func (t *Table) IterateRange(start []byte, limit []byte, fn func(key []byte, value []byte) error) error {
+	tableLimit := util.BytesPrefix([]byte(t.Prefix)).Limit
+	if limit != nil {
+		tableLimit = append([]byte(t.Prefix), limit...)
+	}
+
+	return t.DB.IterateRange(append([]byte(t.Prefix), start...), tableLimit, func(key []byte, value []byte) error {
+		return fn(key[len(t.Prefix):], value)
+	})
+}
+
+// IteratePrefix calls fn for every key in the table starting with prefix.
+func (t *Table) IteratePrefix(prefix []byte, fn func(key []byte, value []byte) error) error {
+	return t.DB.Iterate(append([]byte(t.Prefix), prefix...), func(key []byte, value []byte) error {
+		return fn(key[len(t.Prefix):], value)
+	})
+}
+
+// LastBefore returns the last key in the table starting with prefix and below limit, with
+// the prefix of the table stripped, and its value. Both are nil if there is none.
+func (t *Table) LastBefore(prefix []byte, limit []byte) ([]byte, []byte, error) {
+	key, value, err := t.DB.LastBefore(append([]byte(t.Prefix), prefix...), append([]byte(t.Prefix), limit...))
+	if err != nil || key == nil {
+		return nil, nil, err
+	}
+	return key[len(t.Prefix):], value, nil
+}
@@ -0,0 +1,53 @@
+package barreldb
+
+import (
+	"bytes"
+	"encoding/gob"
+	"github.com/barreleye-labs/barreleye/common"
+	"strconv"
+)
+
+// UndoEntry is the value a state key had before a block was linked.
+// Value is nil if the key did not exist.
+type UndoEntry struct {
+	Table string
+	Key   []byte
+	Value []byte
+}
+
+// HeightUndo Repository
+func (barrelDB *BarrelDatabase) InsertHeightUndo(height int32, entries []UndoEntry) error {
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(entries); err != nil {
+		return err
+	}
+
+	if err := barrelDB.GetTable(HeightUndoTableName).Put([]byte(strconv.Itoa(int(height))), buf.Bytes()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (barrelDB *BarrelDatabase) DeleteHeightUndo(height int32) error {
+	if err := barrelDB.GetTable(HeightUndoTableName).Delete([]byte(strconv.Itoa(int(height)))); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (barrelDB *BarrelDatabase) SelectHeightUndo(height int32) ([]UndoEntry, error) {
+	data, err := barrelDB.GetTable(HeightUndoTableName).Get([]byte(strconv.Itoa(int(height))))
+	if err != nil {
+		if err.Error() != common.LevelDBNotFoundError {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	entries := []UndoEntry{}
+	if err = gob.NewDecoder(bytes.NewBuffer(data)).Decode(&entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -3,10 +3,14 @@ package common
 import (
 	"encoding/hex"
 	"fmt"
+	"strings"
 )
 
 const (
 	AddressLength = 20
+
+	// AddressPrefix is the human-readable part of bech32 addresses.
+	AddressPrefix = "brl"
 )
 
 type Address [AddressLength]byte
@@ -23,6 +27,12 @@ func (a Address) String() string {
 	return hex.EncodeToString(a.ToSlice())
 }
 
+// Bech32 returns the checksummed bech32 form of the address, such as brl1...
+func (a Address) Bech32() string {
+	data, _ := convertBits(a.ToSlice(), 8, 5, true)
+	return bech32Encode(AddressPrefix, data)
+}
+
 func (a Address) Equal(address Address) bool {
 	if a.String() == address.String() {
 		return true
@@ -30,10 +40,10 @@ func (a Address) Equal(address Address) bool {
 	return false
 }
 
-func NewAddressFromBytes(b []byte) Address {
-	if len(b) != 20 {
-		msg := fmt.Sprintf("given bytes with length %d should be 32", len(b))
-		panic(msg)
+// NewAddressFromBytes returns the address of b, which must hold AddressLength bytes.
+func NewAddressFromBytes(b []byte) (Address, error) {
+	if len(b) != AddressLength {
+		return Address{}, fmt.Errorf("given bytes with length %d should be %d", len(b), AddressLength)
 	}
 
 	var value [20]uint8
@@ -41,5 +51,55 @@ func NewAddressFromBytes(b []byte) Address {
 		value[i] = b[i]
 	}
 
-	return value
+	return value, nil
+}
+
+// ParseAddress parses a bech32 address with the brl prefix or an address of 40 hex
+// digits. Hex addresses have no checksum, so inputs that receive value are parsed with
+// ParseChecksummedAddress instead; ParseAddress is left for lookups and older clients.
+func ParseAddress(s string) (Address, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(strings.ToLower(s), AddressPrefix+"1") {
+		return ParseChecksummedAddress(s)
+	}
+
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if len(digits) != AddressLength*2 {
+		return Address{}, fmt.Errorf("invalid address %s: must be a %s1 address or %d hex digits", s, AddressPrefix, AddressLength*2)
+	}
+
+	b, err := hex.DecodeString(digits)
+	if err != nil {
+		return Address{}, fmt.Errorf("invalid address %s: %w", s, err)
+	}
+	return NewAddressFromBytes(b)
+}
+
+// ParseChecksummedAddress parses a bech32 address with the brl prefix only. A bech32
+// address with a typo fails its checksum, so a mistyped recipient is rejected.
+func ParseChecksummedAddress(s string) (Address, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(strings.ToLower(s), AddressPrefix+"1") {
+		return Address{}, fmt.Errorf("invalid address %s: must be a %s1 address, hex addresses have no checksum", s, AddressPrefix)
+	}
+
+	hrp, data, err := bech32Decode(s)
+	if err != nil {
+		return Address{}, fmt.Errorf("invalid address %s: %w", s, err)
+	}
+
+	if hrp != AddressPrefix {
+		return Address{}, fmt.Errorf("invalid address %s: prefix must be %s", s, AddressPrefix)
+	}
+
+	b, err := convertBits(data, 5, 8, false)
+	if err != nil {
+		return Address{}, fmt.Errorf("invalid address %s: must hold %d bytes", s, AddressLength)
+	}
+
+	address, err := NewAddressFromBytes(b)
+	if err != nil {
+		return Address{}, fmt.Errorf("invalid address %s: must hold %d bytes", s, AddressLength)
+	}
+	return address, nil
 }
@@ -0,0 +1,116 @@
+package common
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestBech32Vectors(t *testing.T) {
+	// valid and invalid strings of BIP173
+	valid := []string{
+		"A12UEL5L",
+		"a12uel5l",
+		"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+		"11qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqc8247j",
+		"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+	}
+	for _, s := range valid {
+		hrp, data, err := bech32Decode(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, strings.ToLower(s), bech32Encode(hrp, data))
+	}
+
+	invalid := []string{
+		"an84characterslonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1569pvx",
+		"pzry9x0s0muk",
+		"1pzry9x0s0muk",
+		"x1b4n0q5v",
+		"li1dgmt3",
+		"A1G7SGD8",
+		"10a06t8",
+		"1qzzfhee",
+		"a12UEL5L",
+	}
+	for _, s := range invalid {
+		_, _, err := bech32Decode(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	address, err := NewAddressFromBytes([]byte{
+		0xf4, 0xbc, 0xd6, 0x65, 0xc2, 0x59, 0x5f, 0xb3, 0x25, 0x3a,
+		0xde, 0x20, 0x0b, 0xb8, 0x0d, 0x7e, 0x5d, 0xdd, 0x9c, 0xa2,
+	})
+	assert.Nil(t, err)
+
+	encoded := address.Bech32()
+	assert.True(t, strings.HasPrefix(encoded, "brl1"))
+
+	for _, s := range []string{encoded, strings.ToUpper(encoded), address.String(), "0x" + address.String(), " " + encoded + "\n"} {
+		parsed, err := ParseAddress(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, address, parsed)
+	}
+
+	// every single character typo fails the checksum
+	for i := len(AddressPrefix) + 1; i < len(encoded); i++ {
+		for _, c := range bech32Charset {
+			if byte(c) == encoded[i] {
+				continue
+			}
+			typo := encoded[:i] + string(c) + encoded[i+1:]
+			_, err := ParseAddress(typo)
+			assert.NotNil(t, err, typo)
+		}
+	}
+
+	// swapped neighbours fail as well
+	swapped := encoded[:6] + encoded[7:8] + encoded[6:7] + encoded[8:]
+	if swapped != encoded {
+		_, err := ParseAddress(swapped)
+		assert.NotNil(t, err)
+	}
+
+	data, err := convertBits(address.ToSlice()[:19], 8, 5, true)
+	assert.Nil(t, err)
+	for _, s := range []string{
+		"",
+		encoded[:len(encoded)-1],
+		strings.ToUpper(encoded[:10]) + encoded[10:],
+		bech32Encode("bc", data),
+		bech32Encode(AddressPrefix, data),
+		address.String()[:38],
+		"0x" + address.String() + "00",
+		"zz" + address.String()[2:],
+	} {
+		_, err := ParseAddress(s)
+		assert.NotNil(t, err, s)
+	}
+
+	// recipients must be bech32, hex has no checksum
+	parsed, err := ParseChecksummedAddress(encoded)
+	assert.Nil(t, err)
+	assert.Equal(t, address, parsed)
+	for _, s := range []string{address.String(), "0x" + address.String(), encoded[:len(encoded)-1]} {
+		_, err = ParseChecksummedAddress(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestFromBytesLength(t *testing.T) {
+	for _, size := range []int{0, AddressLength - 1, AddressLength + 1, HashLength} {
+		_, err := NewAddressFromBytes(make([]byte, size))
+		assert.NotNil(t, err, size)
+	}
+
+	for _, size := range []int{0, HashLength - 1, HashLength + 1, AddressLength} {
+		_, err := HashFromBytes(make([]byte, size))
+		assert.NotNil(t, err, size)
+	}
+
+	_, err := HashFromBytes(make([]byte, HashLength))
+	assert.Nil(t, err)
+}
@@ -0,0 +1,228 @@
+package common
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/holiman/uint256"
+)
+
+const (
+	AmountLength = 32
+
+	// MaxDecimals is the most decimal places an amount can be shown with, the largest
+	// power of ten that fits in 256 bits.
+	MaxDecimals = 77
+)
+
+// Amount is an unsigned 256-bit quantity of coins or tokens in their smallest unit.
+// It is a value type, the arithmetic methods return new amounts.
+type Amount struct {
+	v uint256.Int
+}
+
+func NewAmount(value uint64) Amount {
+	var a Amount
+	a.v.SetUint64(value)
+	return a
+}
+
+// CoinAmount returns the amount of whole coins with the given decimals in the smallest unit.
+func CoinAmount(coins uint64, decimals uint8) Amount {
+	a, overflow := NewAmount(coins).Mul(pow10(decimals))
+	if overflow {
+		panic(fmt.Sprintf("%d coins with %d decimals overflow 256 bits", coins, decimals))
+	}
+	return a
+}
+
+// AmountFromBytes reads a big-endian amount of at most 32 bytes.
+func AmountFromBytes(b []byte) (Amount, error) {
+	var a Amount
+	if len(b) > AmountLength {
+		return a, fmt.Errorf("amount of %d bytes is larger than 256 bits", len(b))
+	}
+	a.v.SetBytes(b)
+	return a, nil
+}
+
+// AmountFromBig converts a non-negative big integer of at most 256 bits.
+func AmountFromBig(b *big.Int) (Amount, error) {
+	var a Amount
+	if b.Sign() < 0 {
+		return a, fmt.Errorf("amount can not be negative")
+	}
+	if a.v.SetFromBig(b) {
+		return a, fmt.Errorf("amount is larger than 256 bits")
+	}
+	return a, nil
+}
+
+// ParseAmount parses an amount in the smallest unit, either as hex with a 0x prefix or
+// as a decimal integer.
+func ParseAmount(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	value, ok := new(big.Int), false
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		value, ok = value.SetString(s[2:], 16)
+	} else {
+		value, ok = value.SetString(s, 10)
+	}
+	if !ok {
+		return Amount{}, fmt.Errorf("invalid amount %q", s)
+	}
+	return AmountFromBig(value)
+}
+
+// Add returns a+b and whether the sum overflowed 256 bits.
+func (a Amount) Add(b Amount) (Amount, bool) {
+	var sum Amount
+	_, overflow := sum.v.AddOverflow(&a.v, &b.v)
+	return sum, overflow
+}
+
+// Sub returns a-b and whether it would have been negative.
+func (a Amount) Sub(b Amount) (Amount, bool) {
+	var diff Amount
+	_, underflow := diff.v.SubOverflow(&a.v, &b.v)
+	return diff, underflow
+}
+
+// Mul returns a*b and whether the product overflowed 256 bits.
+func (a Amount) Mul(b Amount) (Amount, bool) {
+	var product Amount
+	_, overflow := product.v.MulOverflow(&a.v, &b.v)
+	return product, overflow
+}
+
+// Div returns a/b, or zero if b is zero.
+func (a Amount) Div(b Amount) Amount {
+	var quotient Amount
+	quotient.v.Div(&a.v, &b.v)
+	return quotient
+}
+
+func (a Amount) Rsh(n uint) Amount {
+	var shifted Amount
+	shifted.v.Rsh(&a.v, n)
+	return shifted
+}
+
+func (a Amount) Cmp(b Amount) int {
+	return a.v.Cmp(&b.v)
+}
+
+func (a Amount) Lt(b Amount) bool {
+	return a.v.Lt(&b.v)
+}
+
+func (a Amount) Gt(b Amount) bool {
+	return a.v.Gt(&b.v)
+}
+
+func (a Amount) IsZero() bool {
+	return a.v.IsZero()
+}
+
+func (a Amount) IsUint64() bool {
+	return a.v.IsUint64()
+}
+
+// Uint64 returns the lower 64 bits of the amount.
+func (a Amount) Uint64() uint64 {
+	return a.v.Uint64()
+}
+
+func (a Amount) ToBig() *big.Int {
+	return a.v.ToBig()
+}
+
+// Bytes32 returns the amount as 32 big-endian bytes.
+func (a Amount) Bytes32() [AmountLength]byte {
+	return a.v.Bytes32()
+}
+
+// Bytes returns the amount as big-endian bytes without leading zeros, one zero byte for zero.
+func (a Amount) Bytes() []byte {
+	b := a.v.Bytes()
+	if len(b) == 0 {
+		b = []byte{0}
+	}
+	return b
+}
+
+// Hex returns the amount as hex without a prefix, the way the API writes numbers.
+func (a Amount) Hex() string {
+	return hex.EncodeToString(a.Bytes())
+}
+
+// String returns the amount as a decimal integer.
+func (a Amount) String() string {
+	return a.v.Dec()
+}
+
+// Format writes the amount in whole units with the given decimals, leaving out trailing
+// zeros of the fraction, so that 1500 with 3 decimals is "1.5".
+func (a Amount) Format(decimals uint8) string {
+	digits := a.v.Dec()
+	if decimals == 0 {
+		return digits
+	}
+
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	whole := digits[:len(digits)-int(decimals)]
+	fraction := strings.TrimRight(digits[len(digits)-int(decimals):], "0")
+	if fraction == "" {
+		return whole
+	}
+	return whole + "." + fraction
+}
+
+func (a Amount) GobEncode() ([]byte, error) {
+	b := a.v.Bytes32()
+	return b[:], nil
+}
+
+func (a *Amount) GobDecode(data []byte) error {
+	amount, err := AmountFromBytes(data)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+// MarshalJSON writes the amount as a decimal string, numbers above 2^53 are not safe in JSON.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON accepts a JSON number or a string parsed by ParseAmount.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if strings.HasPrefix(s, "\"") {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+
+	amount, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+func pow10(decimals uint8) Amount {
+	var ten, exponent, result Amount
+	ten.v.SetUint64(10)
+	exponent.v.SetUint64(uint64(decimals))
+	result.v.Exp(&ten.v, &exponent.v)
+	return result
+}
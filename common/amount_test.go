@@ -0,0 +1,83 @@
+package common
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAmountFormat(t *testing.T) {
+	assert.Equal(t, "1.5", NewAmount(1500).Format(3))
+	assert.Equal(t, "0.001", NewAmount(1).Format(3))
+	assert.Equal(t, "2", NewAmount(2000).Format(3))
+	assert.Equal(t, "0", NewAmount(0).Format(18))
+	assert.Equal(t, "10", CoinAmount(10, 18).Format(18))
+	assert.Equal(t, "42", NewAmount(42).Format(0))
+}
+
+func TestParseAmount(t *testing.T) {
+	a, err := ParseAmount("0x0a")
+	assert.Nil(t, err)
+	assert.Equal(t, NewAmount(10), a)
+
+	a, err = ParseAmount("10")
+	assert.Nil(t, err)
+	assert.Equal(t, NewAmount(10), a)
+
+	max := "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+	a, err = ParseAmount(max)
+	assert.Nil(t, err)
+	assert.Equal(t, max, a.String())
+
+	_, err = ParseAmount("115792089237316195423570985008687907853269984665640564039457584007913129639936")
+	assert.NotNil(t, err)
+	_, err = ParseAmount("-1")
+	assert.NotNil(t, err)
+	_, err = ParseAmount("1.5")
+	assert.NotNil(t, err)
+}
+
+func TestAmountOverflow(t *testing.T) {
+	max, err := ParseAmount("0x" + "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+	assert.Nil(t, err)
+
+	_, overflow := max.Add(NewAmount(1))
+	assert.True(t, overflow)
+
+	_, underflow := NewAmount(1).Sub(NewAmount(2))
+	assert.True(t, underflow)
+
+	diff, underflow := NewAmount(5).Sub(NewAmount(2))
+	assert.False(t, underflow)
+	assert.Equal(t, NewAmount(3), diff)
+}
+
+func TestAmountEncoding(t *testing.T) {
+	type holder struct {
+		Amount Amount `json:"amount"`
+	}
+
+	in := holder{Amount: CoinAmount(80_000_000, 18)}
+
+	buf := new(bytes.Buffer)
+	assert.Nil(t, gob.NewEncoder(buf).Encode(in))
+	out := holder{}
+	assert.Nil(t, gob.NewDecoder(buf).Decode(&out))
+	assert.Equal(t, in, out)
+
+	data, err := json.Marshal(in)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"amount":"80000000000000000000000000"}`, string(data))
+
+	out = holder{}
+	assert.Nil(t, json.Unmarshal(data, &out))
+	assert.Equal(t, in, out)
+
+	assert.Nil(t, json.Unmarshal([]byte(`{"amount":7}`), &out))
+	assert.Equal(t, NewAmount(7), out.Amount)
+	assert.Nil(t, json.Unmarshal([]byte(`{"amount":"0x10"}`), &out))
+	assert.Equal(t, NewAmount(16), out.Amount)
+}
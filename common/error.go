@@ -8,4 +8,21 @@ var (
 	ErrTransactionAlreadyPending = errors.New("this transaction is already pending transaction")
 	ErrBlockTooHigh              = errors.New("block Too high")
 	ErrPrevBlockMismatch         = errors.New("previous block hash of the block to be connected does not match the current block hash")
+	ErrStateRootMismatch         = errors.New("state root of the block does not match the state after executing it")
+	ErrUnknownProducer           = errors.New("block signer is not a validator")
+	ErrBlockTooEarly             = errors.New("block was produced before the slot of its producer")
+	ErrBlockFromFuture           = errors.New("block timestamp is too far in the future")
+	ErrTxFeeTooLow               = errors.New("transaction fee is too low")
+	ErrTxExpired                 = errors.New("transaction has expired")
+	ErrWrongChain                = errors.New("block contains a transaction for another chain")
+	ErrBlockTooManyTxs           = errors.New("block has more transactions than allowed")
+	ErrBlockTooLarge             = errors.New("transactions of the block are larger than allowed")
+	ErrTxDataTooLarge            = errors.New("transaction data is larger than allowed")
+	ErrBlockGasLimit             = errors.New("contract calls of the block buy more gas than allowed")
+	ErrTxTooLarge                = errors.New("transaction is larger than a block")
+	ErrBlockVersion              = errors.New("block version does not match the forks active at its height")
+	ErrTxKnown                   = errors.New("block contains a transaction that is already on the chain")
+	ErrTxDuplicate               = errors.New("block contains the same transaction twice")
+	ErrTxNotIncludable           = errors.New("block contains a transaction that fails without paying its fee")
+	ErrBlockPruned               = errors.New("block has been pruned")
 )
@@ -6,14 +6,22 @@ import (
 
 func ParseFlag() {
 	flag.String("name", "", "node name")
-	flag.String("role", "", "if genesis node -> g, if normal node -> n")
 	flag.String("port", "", "port")
 	flag.String("http.port", "", "http port")
 	flag.String("peers", "", "peers")
-	flag.String("key", "", "private key")
+	flag.String("keyfile", "", "keystore file with the encrypted node key")
+	flag.String("password", "", "file with the password of the key file, prompted for if empty")
+	flag.String("repair", "false", "if true, rebuild the database from its blocks when the startup check finds problems")
+	flag.String("genesis", "genesis.json", "genesis file with the chain parameters, validators and prefunded accounts")
+	flag.String("prune", "0", "number of recent blocks to keep whole, older blocks keep only their headers. 0 keeps all blocks")
 	flag.Parse()
 }
 
+// GetFlag returns the value of the flag, or an empty string if the flags were not parsed.
 func GetFlag(paramName string) string {
-	return flag.Lookup(paramName).Value.(flag.Getter).Get().(string)
+	f := flag.Lookup(paramName)
+	if f == nil {
+		return ""
+	}
+	return f.Value.(flag.Getter).Get().(string)
 }
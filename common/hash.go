@@ -41,15 +41,15 @@ func (h Hash) String() string {
 	return hex.EncodeToString(h.ToSlice())
 }
 
-func HashFromBytes(b []byte) Hash {
-	if len(b) != 32 {
-		msg := fmt.Sprintf("given bytes with length %d should be 32", len(b))
-		panic(msg)
+// HashFromBytes returns the hash of b, which must hold HashLength bytes.
+func HashFromBytes(b []byte) (Hash, error) {
+	if len(b) != HashLength {
+		return Hash{}, fmt.Errorf("given bytes with length %d should be %d", len(b), HashLength)
 	}
 	var value [32]uint8
 	for i := 0; i < 32; i++ {
 		value[i] = b[i]
 	}
 
-	return value
+	return value, nil
 }
@@ -9,4 +9,4 @@ func AssertEqual(a, b any) {
 	if !reflect.DeepEqual(a, b) {
 		log.Fatalf("ASSERTION: %+v != %+v", a, b)
 	}
-}
\ No newline at end of file
+}
@@ -1,7 +1,83 @@
 package config
 
+import (
+	"github.com/barreleye-labs/barreleye/common"
+	"time"
+)
+
+const (
+	faucetCoins = 5
+	gasPerCoin  = 10_000
+
+	// MinDecimals and MaxDecimals bound the decimals of the chain. A coin must split
+	// into gasPerCoin units for the gas price to be at least 1, and faucetCoins coins
+	// must fit into 256 bits.
+	MinDecimals = 4
+	MaxDecimals = 76
+)
+
+// The chain parameters below are the defaults, a node takes them from its genesis file.
 var (
-	BlockReward     = uint64(10)
-	FaucetAmount    = uint64(5)
+	// ChainID identifies the network. It is part of the signed hash of every transaction,
+	// so a transaction signed for one network is rejected by the others.
+	ChainID = uint64(1)
+
+	// Amounts are kept in the smallest unit of the coin, one coin is 10^Decimals of them.
+	// The API shows amounts in coins next to the raw values.
+	Decimals = uint8(18)
+	Symbol   = "BRL"
+
+	// InitialBlockReward is halved every RewardHalvingInterval blocks. No block
+	// reward is minted once the total supply reaches MaxSupply.
+	InitialBlockReward    = common.CoinAmount(10, Decimals)
+	RewardHalvingInterval = int32(4_500_000) // about a year of blocks
+	MaxSupply             = common.CoinAmount(80_000_000, Decimals)
+
+	FaucetAmount    = common.CoinAmount(faucetCoins, Decimals)
 	FaucetDelayTime = int64(60 * 60) // seconds
+
+	MinTxFee = common.CoinAmount(1, Decimals)
+
+	// Blocks hold at most MaxBlockTxs transactions of at most MaxBlockSize encoded bytes
+	// in total, the producer picks the transactions paying the highest fees that fit.
+	// Blocks breaking these limits are rejected.
+	MaxBlockTxs   = 100
+	MaxBlockSize  = 1024 * 1024
+	MaxTxDataSize = 64 * 1024
+
+	// GasPrice is the fee paid for each unit of gas a contract call can use, in the
+	// smallest unit. One coin buys 10,000 gas.
+	GasPrice    = common.CoinAmount(1, Decimals).Div(common.NewAmount(gasPerCoin))
+	MaxCallGas  = uint64(1_000_000) // gas of read-only contract calls
+	MaxCodeSize = 24 * 1024
+
+	// A contract call buys at most MaxTxGas gas, a higher fee buys no more. The gas
+	// bought by the contract calls of a block must not exceed MaxBlockGas.
+	MaxTxGas    = uint64(1_000_000)
+	MaxBlockGas = uint64(10_000_000)
+
+	// A pruned node keeps the bodies of at least MinPruneDepth recent blocks. It takes a
+	// snapshot of the state every SnapshotInterval blocks and keeps the latest SnapshotsKept.
+	MinPruneDepth    = int32(1000)
+	SnapshotInterval = int32(1000)
+	SnapshotsKept    = int32(2)
+
+	BlockTime       = 7 * time.Second
+	ProducerTimeout = 3 * time.Second // time given to each producer before the next one in the schedule steps in
+
+	// GenesisTimestamp is the time of the genesis block in unix seconds. The accounts of
+	// GenesisAlloc start with the given balances.
+	GenesisTimestamp = int64(1704067200)
+	GenesisAlloc     = map[string]common.Amount{}
+
+	// Forks maps the protocol upgrades in fork.go to the height they activate at. A fork
+	// that is not listed is never active.
+	Forks = map[string]int32{}
+
+	// Validators are the addresses allowed to produce blocks.
+	Validators = []string{
+		"56c3c22ce2a0fc3cde6c35b5dcd729c1cccfec37", // barreleye
+		"66c21f3ae79a3632135163d86e9d15c0aed1013f", // nayoung
+		"c319512ce579a9771e14bede318f8e14b85e7af9", // youngmin
+	}
 )
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Forks are the protocol upgrades the node knows. A fork changes the rules for the blocks
+// from its activation height in Forks on, so that an upgrade is rolled out by scheduling
+// it at a future height instead of restarting every node at once.
+const (
+	// ForkChainHash makes block hashes commit to the chain ID. Blocks from it have version 2.
+	ForkChainHash = "chainHash"
+
+	// ForkFeeBurn burns the minimum fee of every transaction and pays only the rest of the
+	// fee to the block producer.
+	ForkFeeBurn = "feeBurn"
+)
+
+// KnownForks lists the forks in the order they were introduced.
+var KnownForks = []string{ForkChainHash, ForkFeeBurn}
+
+// ForkActivation is a fork and the height it is active from.
+type ForkActivation struct {
+	Name   string
+	Height int32
+}
+
+// IsForkActive reports whether the fork is scheduled and active at the height.
+func IsForkActive(name string, height int32) bool {
+	activation, ok := Forks[name]
+	return ok && height >= activation
+}
+
+// BlockVersion returns the header version of blocks at the height.
+func BlockVersion(height int32) uint32 {
+	if IsForkActive(ForkChainHash, height) {
+		return 2
+	}
+	return 1
+}
+
+// ForkSchedule returns the scheduled forks ordered by activation height.
+func ForkSchedule() []ForkActivation {
+	schedule := []ForkActivation{}
+	for _, name := range KnownForks {
+		if height, ok := Forks[name]; ok {
+			schedule = append(schedule, ForkActivation{Name: name, Height: height})
+		}
+	}
+
+	sort.SliceStable(schedule, func(i, j int) bool {
+		return schedule[i].Height < schedule[j].Height
+	})
+	return schedule
+}
+
+func validateForks(forks map[string]int32) error {
+	for name, height := range forks {
+		known := false
+		for _, knownFork := range KnownForks {
+			known = known || knownFork == name
+		}
+
+		if !known {
+			return fmt.Errorf("unknown fork %s", name)
+		}
+
+		if height < 1 {
+			return fmt.Errorf("fork %s must activate after the genesis block", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"github.com/barreleye-labs/barreleye/common"
+	"os"
+	"time"
+)
+
+// Genesis describes the genesis block and the chain parameters every node of the network
+// must agree on. Each node reads it from its genesis file at startup.
+type Genesis struct {
+	ChainID   uint64 `json:"chainId"`
+	Timestamp int64  `json:"timestamp"` // unix time in seconds
+	Symbol    string `json:"symbol"`
+	Decimals  uint8  `json:"decimals"`
+
+	Validators      []string `json:"validators"`
+	BlockTime       Duration `json:"blockTime"`
+	ProducerTimeout Duration `json:"producerTimeout"`
+
+	InitialBlockReward    common.Amount `json:"initialBlockReward"`
+	RewardHalvingInterval int32         `json:"rewardHalvingInterval"`
+	MaxSupply             common.Amount `json:"maxSupply"`
+	MinTxFee              common.Amount `json:"minTxFee"`
+
+	// Alloc maps the hex addresses of the prefunded accounts to their balances.
+	Alloc map[string]common.Amount `json:"alloc"`
+
+	// Forks schedules the protocol upgrades. Unlike the other parameters they are not
+	// part of the genesis hash, upgrades are scheduled on a running chain.
+	Forks map[string]int32 `json:"forks,omitempty"`
+}
+
+// Duration is a time.Duration written as a string like "7s" in the genesis file.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// LoadGenesis reads and validates the genesis file at path. Unknown fields are rejected
+// so that a misspelled parameter does not silently fall back to its default.
+func LoadGenesis(path string) (*Genesis, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	g := &Genesis{}
+	if err = dec.Decode(g); err != nil {
+		return nil, fmt.Errorf("invalid genesis file %s: %w", path, err)
+	}
+
+	if err = g.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid genesis file %s: %w", path, err)
+	}
+	return g, nil
+}
+
+// CurrentGenesis returns the genesis described by the current configuration.
+func CurrentGenesis() *Genesis {
+	alloc := make(map[string]common.Amount, len(GenesisAlloc))
+	for address, balance := range GenesisAlloc {
+		alloc[address] = balance
+	}
+
+	forks := make(map[string]int32, len(Forks))
+	for name, height := range Forks {
+		forks[name] = height
+	}
+
+	return &Genesis{
+		ChainID:               ChainID,
+		Timestamp:             GenesisTimestamp,
+		Symbol:                Symbol,
+		Decimals:              Decimals,
+		Validators:            append([]string{}, Validators...),
+		BlockTime:             Duration(BlockTime),
+		ProducerTimeout:       Duration(ProducerTimeout),
+		InitialBlockReward:    InitialBlockReward,
+		RewardHalvingInterval: RewardHalvingInterval,
+		MaxSupply:             MaxSupply,
+		MinTxFee:              MinTxFee,
+		Alloc:                 alloc,
+		Forks:                 forks,
+	}
+}
+
+// Validate checks the parameters and brings the addresses to the lowercase hex form
+// without a prefix, so that equal files hash equally.
+func (g *Genesis) Validate() error {
+	if g.ChainID == 0 {
+		return fmt.Errorf("chainId must not be 0")
+	}
+
+	if g.Symbol == "" {
+		return fmt.Errorf("symbol must not be empty")
+	}
+
+	if g.Decimals < MinDecimals || g.Decimals > MaxDecimals {
+		return fmt.Errorf("decimals must be between %d and %d", MinDecimals, MaxDecimals)
+	}
+
+	if len(g.Validators) == 0 {
+		return fmt.Errorf("at least one validator is required")
+	}
+
+	for i, validator := range g.Validators {
+		address, err := normalizeAddress(validator)
+		if err != nil {
+			return err
+		}
+		g.Validators[i] = address
+	}
+
+	if g.BlockTime <= 0 || g.ProducerTimeout <= 0 {
+		return fmt.Errorf("blockTime and producerTimeout must be positive")
+	}
+
+	if g.RewardHalvingInterval <= 0 {
+		return fmt.Errorf("rewardHalvingInterval must be positive")
+	}
+
+	alloc := make(map[string]common.Amount, len(g.Alloc))
+	total := common.Amount{}
+	for address, balance := range g.Alloc {
+		normalized, err := normalizeAddress(address)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := alloc[normalized]; ok {
+			return fmt.Errorf("address %s is allocated twice", address)
+		}
+		alloc[normalized] = balance
+
+		var overflow bool
+		if total, overflow = total.Add(balance); overflow {
+			return fmt.Errorf("allocations overflow 256 bits")
+		}
+	}
+	g.Alloc = alloc
+
+	if total.Gt(g.MaxSupply) {
+		return fmt.Errorf("allocations of %s are more than the maximum supply of %s", total, g.MaxSupply)
+	}
+	return validateForks(g.Forks)
+}
+
+// Apply makes the parameters of the genesis the configuration of this node.
+func (g *Genesis) Apply() {
+	ChainID = g.ChainID
+	GenesisTimestamp = g.Timestamp
+	Symbol = g.Symbol
+	Decimals = g.Decimals
+	Validators = g.Validators
+	BlockTime = time.Duration(g.BlockTime)
+	ProducerTimeout = time.Duration(g.ProducerTimeout)
+	InitialBlockReward = g.InitialBlockReward
+	RewardHalvingInterval = g.RewardHalvingInterval
+	MaxSupply = g.MaxSupply
+	MinTxFee = g.MinTxFee
+	GenesisAlloc = g.Alloc
+	Forks = g.Forks
+	if Forks == nil {
+		Forks = map[string]int32{}
+	}
+
+	// amounts given in whole coins follow the decimals of the chain.
+	FaucetAmount = common.CoinAmount(faucetCoins, Decimals)
+	GasPrice = common.CoinAmount(1, Decimals).Div(common.NewAmount(gasPerCoin))
+}
+
+// Hash commits to every parameter of the genesis. The genesis block carries it in place
+// of the hash of a previous block, so that nodes configured differently do not agree on
+// the genesis block.
+func (g *Genesis) Hash() (common.Hash, error) {
+	params := *g
+	params.Forks = nil
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return sha256.Sum256(data), nil
+}
+
+func normalizeAddress(address string) (string, error) {
+	parsed, err := common.ParseAddress(address)
+	if err != nil {
+		return "", err
+	}
+	return parsed.String(), nil
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"bytes"
 	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
 	"github.com/barreleye-labs/barreleye/core/types"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -23,13 +24,14 @@ func TestVerifyBlock(t *testing.T) {
 
 	assert.Nil(t, b.Sign(*alicePrivateKey))
 	assert.Nil(t, b.Verify())
-
-	bobPrivateKey := types.GeneratePrivateKey()
-	b.Signer = bobPrivateKey.PublicKey
-	assert.NotNil(t, b.Verify())
+	assert.Equal(t, alicePrivateKey.PublicKey.Address(), b.Producer())
 
 	b.Height = 100
 	assert.NotNil(t, b.Verify())
+
+	// the signature of another header recovers to another producer
+	b.Hash = common.Hash{}
+	assert.NotEqual(t, alicePrivateKey.PublicKey.Address(), b.Producer())
 }
 
 func randomBlock(t *testing.T, height int32, prevBlockHash common.Hash) *types.Block {
@@ -47,6 +49,7 @@ func randomBlock(t *testing.T, height int32, prevBlockHash common.Hash) *types.B
 	dataHash, err := types.CalculateDataHash(b.Transactions)
 	assert.Nil(t, err)
 	b.Header.DataHash = dataHash
+	b.Hash = common.Hash{}
 	assert.Nil(t, b.Sign(*privateKey))
 	return b
 }
@@ -65,6 +68,47 @@ func TestDecodeEncodeBlock(t *testing.T) {
 		assert.Equal(t, b.Transactions[i], bDecode.Transactions[i])
 	}
 
-	assert.Equal(t, b.Signer, bDecode.Signer)
 	assert.Equal(t, b.Signature, bDecode.Signature)
+	assert.Equal(t, b.Producer(), bDecode.Producer())
+}
+
+func TestValidateBlockLimits(t *testing.T) {
+	newTx := func(dataSize int) *types.Transaction {
+		from := types.GeneratePrivateKey().PublicKey.Address()
+		to := types.GeneratePrivateKey().PublicKey.Address()
+		return types.CreateTransaction(0, from, to, coins(1), coins(1), make([]byte, dataSize))
+	}
+
+	b := &types.Block{Header: &types.Header{}}
+	for i := 0; i < config.MaxBlockTxs; i++ {
+		b.Transactions = append(b.Transactions, newTx(0))
+	}
+	assert.Nil(t, validateBlockLimits(b))
+
+	b.Transactions = append(b.Transactions, newTx(0))
+	assert.Equal(t, common.ErrBlockTooManyTxs, validateBlockLimits(b))
+
+	b.Transactions = []*types.Transaction{newTx(config.MaxTxDataSize + 1)}
+	assert.Equal(t, common.ErrTxDataTooLarge, validateBlockLimits(b))
+
+	b.Transactions = nil
+	for i := 0; i*config.MaxTxDataSize <= config.MaxBlockSize; i++ {
+		b.Transactions = append(b.Transactions, newTx(config.MaxTxDataSize))
+	}
+	assert.Equal(t, common.ErrBlockTooLarge, validateBlockLimits(b))
+
+	// a fee above the gas limit buys no more gas
+	call := newTx(0)
+	call.Type = types.TxTypeCallContract
+	call.Fee = coins(1_000_000)
+	assert.Equal(t, config.MaxTxGas, TxGas(call))
+
+	b.Transactions = nil
+	for i := uint64(0); i < config.MaxBlockGas/config.MaxTxGas; i++ {
+		b.Transactions = append(b.Transactions, call)
+	}
+	assert.Nil(t, validateBlockLimits(b))
+
+	b.Transactions = append(b.Transactions, call)
+	assert.Equal(t, common.ErrBlockGasLimit, validateBlockLimits(b))
 }
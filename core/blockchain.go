@@ -1,12 +1,13 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"github.com/barreleye-labs/barreleye/barreldb"
 	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
 	"github.com/barreleye-labs/barreleye/core/types"
 	"sync"
-	"time"
 
 	"github.com/go-kit/log"
 )
@@ -15,52 +16,76 @@ type Blockchain struct {
 	logger    log.Logger
 	lock      sync.RWMutex
 	validator Validator
+	schedule  *ProducerSchedule
 	db        *barreldb.BarrelDatabase
+
+	// pruneDepth is the number of recent blocks whose bodies are kept, 0 keeps all blocks.
+	pruneDepth int32
 }
 
-func NewBlockchain(l log.Logger, privateKey *types.PrivateKey) (*Blockchain, error) {
+func NewBlockchain(l log.Logger) (*Blockchain, error) {
 	db, _ := barreldb.New()
 
 	if err := setTables(db); err != nil {
 		return nil, err
 	}
 
+	validators, err := ParseValidators(config.Validators)
+	if err != nil {
+		return nil, err
+	}
+
+	pruneDepth, err := parsePruneDepth(common.GetFlag("prune"))
+	if err != nil {
+		return nil, err
+	}
+
 	bc := &Blockchain{
-		logger: l,
-		db:     db,
+		logger:     l,
+		schedule:   NewProducerSchedule(validators, config.BlockTime, config.ProducerTimeout),
+		db:         db,
+		pruneDepth: pruneDepth,
 	}
 	bc.validator = NewBlockValidator(bc)
 
-	publicKey := privateKey.PublicKey
-	coinbase, err := bc.ReadAccountByAddress(publicKey.Address())
-	if err != nil {
+	if err = bc.checkDatabase(); err != nil {
 		return nil, err
 	}
 
-	if coinbase == nil {
-		coinbase = types.CreateAccount(publicKey.Address())
-		if err := bc.WriteAccountWithAddress(publicKey.Address(), coinbase); err != nil {
-			return nil, err
-		}
+	if err = bc.initGenesis(); err != nil {
+		return nil, err
 	}
 
-	if common.GetFlag("role") == "genesis" {
-		lastBlock, err := bc.ReadLastBlock()
-		if err != nil {
-			return nil, err
-		}
+	return bc, nil
+}
 
-		if lastBlock == nil {
-			err = bc.LinkBlockWithoutValidation(CreateGenesisBlock(privateKey))
-			if err != nil {
-				return nil, err
-			}
+// checkDatabase runs the startup consistency check. If it finds problems the database
+// is rebuilt when the node was started with -repair=true, otherwise starting fails.
+// With -repair=true the whole chain and state are checked instead of the last blocks.
+func (bc *Blockchain) checkDatabase() error {
+	repair := common.GetFlag("repair") == "true"
 
-			_ = bc.logger.Log("msg", "🌞 create genesis block")
-		}
+	check := bc.CheckConsistency
+	if repair {
+		check = bc.CheckFullConsistency
 	}
 
-	return bc, nil
+	report, err := check()
+	if err != nil {
+		return err
+	}
+
+	if report.OK() {
+		return nil
+	}
+
+	_ = bc.logger.Log("msg", "database is inconsistent", "report", report.String())
+
+	if !repair {
+		return fmt.Errorf("database is inconsistent, restart with -repair=true to rebuild it up to height %d\n%s", report.ConsistentHeight, report)
+	}
+
+	return bc.Repair(report)
 }
 
 func setTables(db *barreldb.BarrelDatabase) error {
@@ -107,32 +132,95 @@ func setTables(db *barreldb.BarrelDatabase) error {
 		return err
 	}
 
+	err = db.CreateTable(barreldb.HashReceiptTableName, barreldb.HashReceiptPrefix)
+	if err != nil {
+		return err
+	}
+
 	err = db.CreateTable(barreldb.AddressAccountTableName, barreldb.AddressAccountPrefix)
 	if err != nil {
 		return err
 	}
-	return nil
-}
+	err = db.CreateTable(barreldb.AddressHeightAccountTableName, barreldb.AddressHeightAccountPrefix)
+	if err != nil {
+		return err
+	}
+	err = db.CreateTable(barreldb.ChainSupplyTableName, barreldb.ChainSupplyPrefix)
+	if err != nil {
+		return err
+	}
 
-func CreateGenesisBlock(privateKey *types.PrivateKey) *types.Block {
-	header := &types.Header{
-		Version:   1,
-		Height:    0,
-		Timestamp: time.Now().UnixNano(),
+	err = db.CreateTable(barreldb.SymbolTokenTableName, barreldb.SymbolTokenPrefix)
+	if err != nil {
+		return err
+	}
+	err = db.CreateTable(barreldb.AddressTokenBalanceTableName, barreldb.AddressTokenBalancePrefix)
+	if err != nil {
+		return err
 	}
 
-	b, _ := types.NewBlock(header, nil)
+	err = db.CreateTable(barreldb.AddressMultisigTableName, barreldb.AddressMultisigPrefix)
+	if err != nil {
+		return err
+	}
 
-	if err := b.Sign(*privateKey); err != nil {
-		panic(err)
+	err = db.CreateTable(barreldb.HashLockTableName, barreldb.HashLockPrefix)
+	if err != nil {
+		return err
 	}
-	return b
+	err = db.CreateTable(barreldb.HeightLockTableName, barreldb.HeightLockPrefix)
+	if err != nil {
+		return err
+	}
+	err = db.CreateTable(barreldb.TimeLockTableName, barreldb.TimeLockPrefix)
+	if err != nil {
+		return err
+	}
+
+	err = db.CreateTable(barreldb.AddressCodeTableName, barreldb.AddressCodePrefix)
+	if err != nil {
+		return err
+	}
+	err = db.CreateTable(barreldb.AddressStorageTableName, barreldb.AddressStoragePrefix)
+	if err != nil {
+		return err
+	}
+	err = db.CreateTable(barreldb.StateTrieTableName, barreldb.StateTriePrefix)
+	if err != nil {
+		return err
+	}
+
+	err = db.CreateTable(barreldb.HeightUndoTableName, barreldb.HeightUndoPrefix)
+	if err != nil {
+		return err
+	}
+	err = db.CreateTable(barreldb.SnapshotTableName, barreldb.SnapshotPrefix)
+	if err != nil {
+		return err
+	}
+	err = db.CreateTable(barreldb.SnapshotStateTableName, barreldb.SnapshotStatePrefix)
+	if err != nil {
+		return err
+	}
+	err = db.CreateTable(barreldb.PruneMarkTableName, barreldb.PruneMarkPrefix)
+	if err != nil {
+		return err
+	}
+	return nil
 }
 
 func (bc *Blockchain) SetValidator(v Validator) {
 	bc.validator = v
 }
 
+func (bc *Blockchain) SetSchedule(s *ProducerSchedule) {
+	bc.schedule = s
+}
+
+func (bc *Blockchain) Schedule() *ProducerSchedule {
+	return bc.schedule
+}
+
 func (bc *Blockchain) LinkBlock(b *types.Block) error {
 	bc.lock.Lock()
 	defer bc.lock.Unlock()
@@ -144,99 +232,368 @@ func (bc *Blockchain) LinkBlock(b *types.Block) error {
 	return bc.LinkBlockWithoutValidation(b)
 }
 
-func (bc *Blockchain) handleTransaction(tx *types.Transaction) error {
-	if tx.From.Equal(tx.To) {
-		return fmt.Errorf("from and to must be different")
+func (bc *Blockchain) handleTransaction(state *State, tx *types.Transaction, ctx *TxContext) error {
+	if tx.IsExpired(ctx.Height) {
+		return types.NewTxError(types.ErrCodeExpired, fmt.Sprintf("tx expired at height %d", tx.ValidUntil))
 	}
 
-	fromAccount, err := bc.ReadAccountByAddress(tx.From)
+	if tx.Fee.Lt(config.MinTxFee) {
+		return types.NewTxError(types.ErrCodeFeeTooLow, "tx fee is lower than the minimum fee")
+	}
+
+	policy, err := state.GetMultisigPolicy(tx.From)
 	if err != nil {
 		return err
 	}
 
-	if fromAccount == nil {
-		fromAccount = types.CreateAccount(tx.From)
-		if err = bc.WriteAccountWithAddress(fromAccount.Address, fromAccount); err != nil {
-			return err
-		}
+	if err = AuthorizeSender(policy, tx); err != nil {
+		return err
+	}
+
+	fromAccount, err := state.GetOrCreateAccount(tx.From)
+	if err != nil {
+		return err
 	}
 
 	if fromAccount.Nonce != tx.Nonce {
-		return fmt.Errorf("invalid tx nonce")
+		return types.NewTxError(types.ErrCodeInvalidNonce, "invalid tx nonce")
+	}
+
+	if cost, overflow := tx.Cost(); overflow || fromAccount.Balance.Lt(cost) {
+		return types.NewTxError(types.ErrCodeInsufficientBalance, "insufficient account balance")
 	}
 
-	toAccount, err := bc.ReadAccountByAddress(tx.To)
+	if err = ValidateTransaction(tx); err != nil {
+		return err
+	}
+
+	if err = txHandlers[tx.Type].Execute(bc, state, ctx, tx); err != nil {
+		return err
+	}
+
+	return payFee(state, tx, ctx.BlockContext)
+}
+
+// executeTransaction applies the transaction to the state and returns its receipt.
+// If the transaction fails its changes are discarded, but the fee is still paid to the
+// coinbase and the nonce is used up, so that failing transactions are not free.
+// Transactions with an invalid nonce, a fee below the minimum or missing multisig
+// signatures change nothing.
+func (bc *Blockchain) executeTransaction(state *State, tx *types.Transaction, index int, block BlockContext) (*types.Receipt, error) {
+	addresses := []common.Address{tx.From}
+	for _, address := range []common.Address{tx.To, block.Coinbase} {
+		if !containsAddress(addresses, address) {
+			addresses = append(addresses, address)
+		}
+	}
+
+	before, err := readBalances(state, addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &types.Receipt{
+		TxHash: tx.GetHash(),
+		Index:  uint32(index),
+		Status: types.ReceiptStatusSuccess,
+	}
+
+	ctx := &TxContext{BlockContext: block}
+	snapshot := state.Snapshot()
+	if err = bc.handleTransaction(state, tx, ctx); err != nil {
+		var txErr *types.TxError
+		if !errors.As(err, &txErr) {
+			return nil, err
+		}
+
+		_ = bc.logger.Log("msg", "transaction failed", "hash", tx.GetHash(), "error", txErr.Message)
+		state.RevertToSnapshot(snapshot)
+
+		receipt.Status = types.ReceiptStatusFailed
+		receipt.ErrorCode = txErr.Code
+		receipt.Error = txErr.Message
+
+		if paysFee(txErr.Code) {
+			if err = payFee(state, tx, block); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		receipt.ContractAddress = ctx.ContractAddress
+	}
+	receipt.GasUsed = ctx.GasUsed
+
+	after, err := readBalances(state, addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, address := range addresses {
+		if before[i].Cmp(after[i]) != 0 {
+			receipt.BalanceChanges = append(receipt.BalanceChanges, types.BalanceChange{
+				Address: address,
+				Before:  before[i],
+				After:   after[i],
+			})
+		}
+	}
+	return receipt, nil
+}
+
+// paysFee reports whether a transaction failing with the code still pays its fee.
+// Transactions that the sender never validly authorized for this block do not.
+func paysFee(code types.ErrorCode) bool {
+	switch code {
+	case types.ErrCodeInvalidNonce, types.ErrCodeFeeTooLow, types.ErrCodeUnauthorized, types.ErrCodeExpired:
+		return false
+	}
+	return true
+}
+
+func readBalances(state *State, addresses []common.Address) ([]common.Amount, error) {
+	balances := []common.Amount{}
+	for _, address := range addresses {
+		account, err := state.GetOrCreateAccount(address)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, account.Balance)
+	}
+	return balances, nil
+}
+
+func containsAddress(addresses []common.Address, address common.Address) bool {
+	for _, a := range addresses {
+		if a.Equal(address) {
+			return true
+		}
+	}
+	return false
+}
+
+// payFee moves the fee of the transaction from the sender to the coinbase and uses up
+// the nonce of the sender. A sender that can not afford the whole fee pays its balance.
+// Once config.ForkFeeBurn is active the minimum fee is burned and only the rest is paid
+// to the coinbase.
+func payFee(state *State, tx *types.Transaction, block BlockContext) error {
+	fromAccount, err := state.GetOrCreateAccount(tx.From)
 	if err != nil {
 		return err
 	}
 
-	if toAccount == nil {
-		toAccount = types.CreateAccount(tx.To)
-		if err = bc.WriteAccountWithAddress(toAccount.Address, toAccount); err != nil {
+	fee := tx.Fee
+	if fee.Gt(fromAccount.Balance) {
+		fee = fromAccount.Balance
+	}
+
+	if err = fromAccount.SubBalance(fee); err != nil {
+		return err
+	}
+	fromAccount.Nonce++
+	if err = state.SetAccount(fromAccount); err != nil {
+		return err
+	}
+
+	if config.IsForkActive(config.ForkFeeBurn, block.Height) {
+		burned := config.MinTxFee
+		if burned.Gt(fee) {
+			burned = fee
+		}
+
+		if err = burnCoins(state, burned); err != nil {
 			return err
 		}
+		fee, _ = fee.Sub(burned)
 	}
 
-	if err = fromAccount.Transfer(toAccount, tx.Value); err != nil {
+	coinbaseAccount, err := state.GetOrCreateAccount(block.Coinbase)
+	if err != nil {
 		return err
 	}
 
-	fromAccount.Nonce++
+	if err = coinbaseAccount.AddBalance(fee); err != nil {
+		return err
+	}
+	return state.SetAccount(coinbaseAccount)
+}
+
+// burnCoins takes the burned coins out of the total supply, they can be minted again
+// as block rewards.
+func burnCoins(state *State, amount common.Amount) error {
+	supply, err := state.GetTotalSupply()
+	if err != nil {
+		return err
+	}
+
+	supply, underflow := supply.Sub(amount)
+	if underflow {
+		supply = common.Amount{}
+	}
+	state.SetTotalSupply(supply)
+	return nil
+}
+
+// executeBlock releases the locks that are due, applies the transactions of the block
+// and the block reward, or the allocations for the genesis block, to the state and returns the receipts of the transactions.
+// A block with a known or repeated transaction or with a transaction that fails
+// without paying its fee is rejected with a BlockTxError.
+func (bc *Blockchain) executeBlock(state *State, b *types.Block, coinbase common.Address) ([]*types.Receipt, error) {
+	if err := bc.checkBlockTxs(b); err != nil {
+		return nil, err
+	}
+
+	if err := bc.releaseLocks(state, b.Height, b.Timestamp); err != nil {
+		return nil, err
+	}
+
+	receipts := []*types.Receipt{}
+	for i, tx := range b.Transactions {
+		receipt, err := bc.executeTransaction(state, tx, i, BlockContext{
+			Height:    b.Height,
+			Timestamp: b.Timestamp,
+			Coinbase:  coinbase,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// only transactions that pay their fee may fail in a block, the others would be
+		// recorded for free.
+		if receipt.Status == types.ReceiptStatusFailed && !paysFee(receipt.ErrorCode) {
+			return nil, &types.BlockTxError{
+				Hash: tx.GetHash(),
+				Err:  fmt.Errorf("%w: %s", common.ErrTxNotIncludable, receipt.Error),
+			}
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	// the genesis block mints the allocations of the genesis file instead of a reward.
+	if b.Height == 0 {
+		return receipts, applyGenesisAlloc(state)
+	}
+
+	if err := bc.giveReward(state, coinbase, b.Height); err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// checkBlockTxs rejects a block that contains a transaction of an earlier block or
+// the same transaction twice.
+func (bc *Blockchain) checkBlockTxs(b *types.Block) error {
+	seen := make(map[common.Hash]bool, len(b.Transactions))
+	for _, tx := range b.Transactions {
+		hash := tx.GetHash()
+		if seen[hash] {
+			return &types.BlockTxError{Hash: hash, Err: common.ErrTxDuplicate}
+		}
+		seen[hash] = true
+
+		known, err := bc.db.SelectHashTx(hash)
+		if err != nil {
+			return err
+		}
+
+		if known != nil {
+			return &types.BlockTxError{Hash: hash, Err: common.ErrTxKnown}
+		}
+	}
+	return nil
+}
 
-	if err = bc.WriteAccountWithAddress(fromAccount.Address, fromAccount); err != nil {
+// FinalizeBlock executes a newly produced block on top of the current chain and fills
+// in the data hash and the state root of its header. It must be called before the
+// block is signed.
+func (bc *Blockchain) FinalizeBlock(b *types.Block, coinbase common.Address) error {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	if err := validateBlockLimits(b); err != nil {
 		return err
 	}
-	if err = bc.WriteAccountWithAddress(toAccount.Address, toAccount); err != nil {
+
+	state := NewState(bc.db)
+	if _, err := bc.executeBlock(state, b, coinbase); err != nil {
 		return err
 	}
 
-	_ = bc.logger.Log(
-		"msg", "transfer",
-		"from", fromAccount.Address,
-		"to", toAccount.Address,
-		"value", tx.Value)
+	dataHash, err := types.CalculateDataHash(b.Transactions)
+	if err != nil {
+		return err
+	}
 
+	stateRoot, err := state.Root()
+	if err != nil {
+		return err
+	}
+
+	b.DataHash = dataHash
+	b.StateRoot = stateRoot
+	b.Hash = common.Hash{}
 	return nil
 }
 
 func (bc *Blockchain) LinkBlockWithoutValidation(b *types.Block) error {
-	for i := 0; i < len(b.Transactions); i++ {
-		if err := bc.handleTransaction(b.Transactions[i]); err != nil {
-			_ = bc.logger.Log("error", err.Error())
+	state := NewState(bc.db)
+	receipts, err := bc.executeBlock(state, b, b.Producer())
+	if err != nil {
+		return err
+	}
 
-			b.Transactions[i] = b.Transactions[len(b.Transactions)-1]
-			b.Transactions = b.Transactions[:len(b.Transactions)-1]
-			i--
-		}
+	stateRoot, err := state.Root()
+	if err != nil {
+		return err
+	}
+
+	if !stateRoot.Equal(b.StateRoot) {
+		return common.ErrStateRootMismatch
 	}
 
-	if err := bc.WriteBlockWithHash(b.GetHash(), b); err != nil {
+	// every write of the block goes through one batch so that a crash can not leave
+	// the block partially linked.
+	batch := bc.db.NewBatch()
+
+	undo, err := state.Commit(batch)
+	if err != nil {
 		return err
 	}
-	if err := bc.WriteBlockWithHeight(b.Height, b); err != nil {
+	if err = batch.InsertHeightUndo(b.Height, undo); err != nil {
 		return err
 	}
-	if err := bc.WriteLastBlock(b); err != nil {
+	if err = writeAccountHistory(batch, b.Height, undo); err != nil {
 		return err
 	}
 
-	if err := bc.WriteHeaderWithHash(b.GetHash(), b.Header); err != nil {
+	if err = batch.InsertHashBlock(b.GetHash(), b); err != nil {
 		return err
 	}
-	if err := bc.WriteHeaderWithHeight(b.Height, b.Header); err != nil {
+	if err = batch.InsertHeightBlock(b.Height, b); err != nil {
 		return err
 	}
-	if err := bc.WriteLastHeader(b.Header); err != nil {
+	if err = batch.InsertLastBlock(b); err != nil {
 		return err
 	}
 
-	if err := bc.GiveReward(b.Signer.Address()); err != nil {
+	if err = batch.InsertHashHeader(b.GetHash(), b.Header); err != nil {
+		return err
+	}
+	if err = batch.InsertHeightHeader(b.Height, b.Header); err != nil {
+		return err
+	}
+	if err = batch.InsertLastHeader(b.Header); err != nil {
 		return err
 	}
 
-	for _, tx := range b.Transactions {
+	for i, tx := range b.Transactions {
+		receipts[i].BlockHash = b.GetHash()
+		receipts[i].BlockHeight = b.Height
+		if err = batch.InsertHashReceipt(tx.GetHash(), receipts[i]); err != nil {
+			return err
+		}
+
 		nextTxNumber := uint32(0)
-		lastTxNumber, err := bc.ReadLastTxNumber()
+		lastTxNumber, err := batch.SelectLastTxNumber()
 		if err != nil {
 			return err
 		}
@@ -245,31 +602,34 @@ func (bc *Blockchain) LinkBlockWithoutValidation(b *types.Block) error {
 			nextTxNumber = *lastTxNumber + 1
 		}
 
-		if err = bc.WriteTxWithHash(tx.GetHash(), tx); err != nil {
+		if err = batch.InsertHashTx(tx.GetHash(), tx); err != nil {
+			return err
+		}
+		if err = batch.InsertNumberTx(nextTxNumber, tx); err != nil {
 			return err
 		}
-		if err = bc.WriteTxWithNumber(nextTxNumber, tx); err != nil {
+		if err = batch.UpsertLastTx(tx); err != nil {
 			return err
 		}
-		if err = bc.WriteLastTx(tx); err != nil {
+		if err = batch.UpsertLastTxNumber(nextTxNumber); err != nil {
 			return err
 		}
-		if err = bc.WriteLastTxNumber(nextTxNumber); err != nil {
+	}
+
+	if bc.pruneDepth > 0 {
+		if err = bc.prune(batch, b.Height-bc.pruneDepth); err != nil {
 			return err
 		}
+		if b.Height%config.SnapshotInterval == 0 {
+			if err = writeSnapshot(batch, state, b.Height); err != nil {
+				return err
+			}
+		}
 	}
 
-	/*	check sync account status
-		barreleyeKey, _ := types.CreatePrivateKey("a2288db63c7016b815c55c1084c2491b8599834500408ba863ec379895373ae9")
-		barreleye, _ := bc.ReadAccountByAddress(barreleyeKey.PublicKey.Address())
-		fmt.Println("barreleye: ", barreleye)
-		nayoungKey, _ := types.CreatePrivateKey("c4e0f3f39c5438d2f7ba8b830f5a5538c6a63c752cb36fb1b91911539af01421")
-		nayoung, _ := bc.ReadAccountByAddress(nayoungKey.PublicKey.Address())
-		fmt.Println("nayoung: ", nayoung)
-		youngminKey, _ := types.CreatePrivateKey("f2e1e4331b10c2b84a8ed58226398f5d11ee78052afa641d16851bd66bbdadb7")
-		youngmin, _ := bc.ReadAccountByAddress(youngminKey.PublicKey.Address())
-		fmt.Println("youngmin: ", youngmin)
-	*/
+	if err = batch.Commit(); err != nil {
+		return err
+	}
 
 	_ = bc.logger.Log(
 		"msg", "🔗 link new block",
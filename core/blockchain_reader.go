@@ -24,6 +24,23 @@ func (bc *Blockchain) ReadBlockByHeight(height int32) (*types.Block, error) {
 	return block, nil
 }
 
+// ReadBlockHashByHeight returns the hash of the block of the height, which is known even
+// if the block has been pruned.
+func (bc *Blockchain) ReadBlockHashByHeight(height int32) (*common.Hash, error) {
+	return bc.db.SelectHeightBlockHash(height)
+}
+
+// IsPrunedHeight reports whether the body of the block of the height has been pruned.
+func (bc *Blockchain) IsPrunedHeight(height int32) (bool, error) {
+	mark, err := bc.db.SelectPruneMark()
+	if err != nil {
+		return false, err
+	}
+	return mark != nil && height < mark.Height, nil
+}
+
+// ReadBlocks returns a page of blocks from the last one down. A block whose body has
+// been pruned is returned with its header and hash only, without transactions or signature.
 func (bc *Blockchain) ReadBlocks(page int, size int) ([]*types.Block, error) {
 
 	offset := (page - 1) * size
@@ -56,7 +73,9 @@ func (bc *Blockchain) ReadBlocks(page int, size int) ([]*types.Block, error) {
 			return nil, err
 		}
 		if block == nil {
-			return nil, fmt.Errorf("block %d is nil", i)
+			if block, err = bc.readPrunedBlock(int32(i)); err != nil {
+				return nil, err
+			}
 		}
 		blocks = append(blocks, block)
 	}
@@ -64,6 +83,30 @@ func (bc *Blockchain) ReadBlocks(page int, size int) ([]*types.Block, error) {
 	return blocks, nil
 }
 
+func (bc *Blockchain) readPrunedBlock(height int32) (*types.Block, error) {
+	pruned, err := bc.IsPrunedHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	if !pruned {
+		return nil, fmt.Errorf("block %d is nil", height)
+	}
+
+	header, err := bc.ReadHeaderByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := bc.ReadBlockHashByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil || hash == nil {
+		return nil, fmt.Errorf("header %d of a pruned block is nil", height)
+	}
+
+	return &types.Block{Header: header, Hash: *hash}, nil
+}
+
 // 사용시 수정 필요함.
 func (bc *Blockchain) ReadBlocksByHash(hash common.Hash, size int) ([]*types.Block, error) {
 	blocks := []*types.Block{}
@@ -191,6 +234,15 @@ func (bc *Blockchain) ReadTxByHash(hash common.Hash) (*types.Transaction, error)
 	return tx, nil
 }
 
+func (bc *Blockchain) ReadReceiptByTxHash(hash common.Hash) (*types.Receipt, error) {
+	receipt, err := bc.db.SelectHashReceipt(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
 func (bc *Blockchain) ReadTxByNumber(number uint32) (*types.Transaction, error) {
 	tx, err := bc.db.SelectNumberTx(number)
 	if err != nil {
@@ -265,6 +317,59 @@ func (bc *Blockchain) ReadAccountByAddress(address common.Address) (*types.Accou
 	return account, nil
 }
 
+// ReadAccountAtHeight returns the account the address had after the block of the height,
+// or nil if it did not exist yet.
+func (bc *Blockchain) ReadAccountAtHeight(address common.Address, height int32) (*types.Account, error) {
+	lastBlock, err := bc.db.SelectLastBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if lastBlock == nil || height < 0 || height > lastBlock.Height {
+		return nil, fmt.Errorf("no block at height %d", height)
+	}
+
+	return bc.db.SelectAddressAccountAtHeight(address, height)
+}
+
+func (bc *Blockchain) ReadTotalSupply() (common.Amount, error) {
+	return NewState(bc.db).GetTotalSupply()
+}
+
+func (bc *Blockchain) ReadToken(symbol string) (*types.Token, error) {
+	return NewState(bc.db).GetToken(symbol)
+}
+
+func (bc *Blockchain) ReadMultisigPolicy(address common.Address) (*types.MultisigPolicy, error) {
+	return NewState(bc.db).GetMultisigPolicy(address)
+}
+
+// ReadCode returns the code of the contract at the address, or nil if there is none.
+func (bc *Blockchain) ReadCode(address common.Address) ([]byte, error) {
+	return NewState(bc.db).GetCode(address)
+}
+
+// ReadLocks returns the locks held for the address and the hashes of the transactions that created them.
+func (bc *Blockchain) ReadLocks(address common.Address) ([]common.Hash, []*types.Lock, error) {
+	hashes := []common.Hash{}
+	locks := []*types.Lock{}
+	err := NewState(bc.db).Locks(func(hash common.Hash, lock *types.Lock) error {
+		if lock.Address.Equal(address) {
+			hashes = append(hashes, hash)
+			locks = append(locks, lock)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return hashes, locks, nil
+}
+
+func (bc *Blockchain) ReadTokenBalances(address common.Address) (map[string]common.Amount, error) {
+	return NewState(bc.db).TokenBalances(address)
+}
+
 func (bc *Blockchain) ReadAccountNonceByAddress(address common.Address) (*uint64, error) {
 	account, err := bc.db.SelectAddressAccount(address)
 	if err != nil {
@@ -277,14 +382,14 @@ func (bc *Blockchain) ReadAccountNonceByAddress(address common.Address) (*uint64
 	return &account.Nonce, nil
 }
 
-func (bc *Blockchain) ReadBalance(address common.Address) (*uint64, error) {
+func (bc *Blockchain) ReadBalance(address common.Address) (*common.Amount, error) {
 	account, err := bc.ReadAccountByAddress(address)
 	if err != nil {
 		return nil, err
 	}
 
 	if account == nil {
-		bal := uint64(0)
+		bal := common.Amount{}
 		return &bal, nil
 	}
 
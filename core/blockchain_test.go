@@ -3,6 +3,7 @@ package core
 import (
 	"github.com/barreleye-labs/barreleye/barreldb"
 	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
 	"github.com/barreleye-labs/barreleye/core/types"
 	"github.com/go-kit/log"
 	"github.com/stretchr/testify/assert"
@@ -10,46 +11,188 @@ import (
 )
 
 func TestAddBlockToHeight(t *testing.T) {
-	_ = barreldb.RemoveData("data")
+	bc, key := newProducingChain(t)
 
-	bc := newBlockchainWithGenesis(t)
-	defer bc.db.Close()
+	assert.Nil(t, bc.LinkBlock(produceBlock(t, bc, key, nil)))
 
-	assert.Nil(t, bc.LinkBlock(randomBlock(t, 1, getPrevBlockHash(t, bc, int32(1)))))
-	assert.NotNil(t, bc.LinkBlock(randomBlock(t, 3, common.Hash{})))
+	b := produceBlock(t, bc, key, nil)
+	b.Height += 2
+	b.Hash = common.Hash{}
+	assert.Nil(t, b.Sign(*key))
+	assert.Equal(t, common.ErrBlockTooHigh, bc.LinkBlock(b))
 }
 
 func newBlockchainWithGenesis(t *testing.T) *Blockchain {
-	pk := types.GeneratePrivateKey()
-	bc, err := NewBlockchain(log.NewNopLogger(), pk)
+	bc, err := NewBlockchain(log.NewNopLogger())
 	assert.Nil(t, err)
 
 	return bc
 }
 
-func getPrevBlockHash(t *testing.T, bc *Blockchain, height int32) common.Hash {
-	prevHeader, err := bc.ReadHeaderByHeight(height - 1)
+// newProducingChain returns an in-memory blockchain with a genesis block and a single
+// validator whose key is returned. The genesis block gives the validator 100 coins.
+func newProducingChain(t *testing.T) (*Blockchain, *types.PrivateKey) {
+	key := types.GeneratePrivateKey()
+
+	alloc := config.GenesisAlloc
+	t.Cleanup(func() { config.GenesisAlloc = alloc })
+	config.GenesisAlloc = map[string]common.Amount{key.PublicKey.Address().String(): coins(100)}
+
+	bc := newMemoryChain(t)
+	bc.SetSchedule(NewProducerSchedule([]common.Address{key.PublicKey.Address()}, config.BlockTime, config.ProducerTimeout))
+	bc.SetValidator(NewBlockValidator(bc))
+
+	genesis, err := bc.CreateGenesisBlock()
 	assert.Nil(t, err)
-	return types.BlockHasher{}.Hash(prevHeader)
+	assert.Nil(t, bc.LinkBlockWithoutValidation(genesis))
+	return bc, key
 }
 
-func TestAddBlock(t *testing.T) {
-	_ = barreldb.RemoveData("data")
+// produceBlock builds, finalizes and signs the next block of the validator key in the
+// first slot after the last block.
+func produceBlock(t *testing.T, bc *Blockchain, key *types.PrivateKey, txs []*types.Transaction) *types.Block {
+	prevHeader, err := bc.ReadLastHeader()
+	assert.Nil(t, err)
 
-	bc := newBlockchainWithGenesis(t)
-	defer bc.db.Close()
+	b, err := types.NewBlockFromPrevHeader(prevHeader, txs)
+	assert.Nil(t, err)
+	b.Timestamp = prevHeader.Timestamp + config.BlockTime.Nanoseconds()
+
+	assert.Nil(t, bc.FinalizeBlock(b, key.PublicKey.Address()))
+	assert.Nil(t, b.Sign(*key))
+	return b
+}
+
+func TestAddBlock(t *testing.T) {
+	bc, key := newProducingChain(t)
 
 	lenBlocks := 1000
 	for i := 0; i < lenBlocks; i++ {
-		block := randomBlock(t, int32(i+1), getPrevBlockHash(t, bc, int32(i+1)))
-		assert.Nil(t, bc.LinkBlock(block))
+		assert.Nil(t, bc.LinkBlock(produceBlock(t, bc, key, nil)))
 	}
 
-	lastBlockHeight, _ := bc.ReadLastBlockHeight()
+	lastBlockHeight, err := bc.ReadLastBlockHeight()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(lenBlocks), *lastBlockHeight)
+
+	old, err := bc.ReadBlockByHeight(10)
+	assert.Nil(t, err)
+	assert.Equal(t, common.ErrBlockKnown, bc.LinkBlock(old))
+}
+
+func TestBlockTxRejected(t *testing.T) {
+	bc, key := newProducingChain(t)
+	from := key.PublicKey.Address()
+	to := types.GeneratePrivateKey().PublicKey.Address()
+
+	newTx := func(nonce uint64) *types.Transaction {
+		tx := types.CreateTransaction(nonce, from, to, coins(1), coins(1), nil)
+		assert.Nil(t, tx.Sign(key))
+		return tx
+	}
+
+	first := newTx(0)
+	assert.Nil(t, bc.LinkBlock(produceBlock(t, bc, key, []*types.Transaction{first})))
+
+	receipt, err := bc.ReadReceiptByTxHash(first.GetHash())
+	assert.Nil(t, err)
+	assert.Equal(t, types.ReceiptStatusSuccess, receipt.Status)
+
+	expired := types.CreateTransaction(1, from, to, coins(1), coins(1), nil)
+	expired.ValidUntil = 1
+	assert.Nil(t, expired.Sign(key))
+
+	second := newTx(1)
+	future := newTx(5)
+	cases := []struct {
+		txs    []*types.Transaction
+		reject *types.Transaction
+		err    error
+	}{
+		{[]*types.Transaction{first}, first, common.ErrTxKnown},
+		{[]*types.Transaction{second, second}, second, common.ErrTxDuplicate},
+		{[]*types.Transaction{second, future}, future, common.ErrTxNotIncludable},
+		{[]*types.Transaction{expired}, expired, common.ErrTxNotIncludable},
+	}
+
+	prevHeader, err := bc.ReadLastHeader()
+	assert.Nil(t, err)
+
+	for _, c := range cases {
+		b, err := types.NewBlockFromPrevHeader(prevHeader, c.txs)
+		assert.Nil(t, err)
+
+		err = bc.FinalizeBlock(b, from)
+		assert.ErrorIs(t, err, c.err)
+
+		var txErr *types.BlockTxError
+		assert.ErrorAs(t, err, &txErr)
+		assert.Equal(t, c.reject.GetHash(), txErr.Hash)
+
+		// a received block is rejected the same way
+		b.Timestamp = prevHeader.Timestamp + config.BlockTime.Nanoseconds()
+		b.Hash = common.Hash{}
+		assert.Nil(t, b.Sign(*key))
+		assert.ErrorIs(t, bc.LinkBlock(b), c.err)
+	}
+
+	// a transaction signed by someone else than its sender is not included either
+	forged := types.CreateTransaction(1, from, to, coins(1), coins(1), nil)
+	assert.Nil(t, forged.Sign(types.GeneratePrivateKey()))
+
+	b, err := types.NewBlockFromPrevHeader(prevHeader, []*types.Transaction{forged})
+	assert.Nil(t, err)
+	assert.ErrorIs(t, bc.FinalizeBlock(b, from), common.ErrTxNotIncludable)
+
+	b.Timestamp = prevHeader.Timestamp + config.BlockTime.Nanoseconds()
+	b.Hash = common.Hash{}
+	assert.Nil(t, b.Sign(*key))
+	assert.NotNil(t, bc.LinkBlock(b))
 
-	assert.Equal(t, *lastBlockHeight, int32(lenBlocks))
-	assert.Equal(t, *lastBlockHeight+1, lenBlocks)
-	assert.NotNil(t, bc.LinkBlock(randomBlock(t, 10, common.Hash{})))
+	receipt, err = bc.ReadReceiptByTxHash(first.GetHash())
+	assert.Nil(t, err)
+	assert.Equal(t, types.ReceiptStatusSuccess, receipt.Status)
+	assert.Equal(t, int32(1), receipt.BlockHeight)
+
+	assert.Nil(t, bc.LinkBlock(produceBlock(t, bc, key, []*types.Transaction{second})))
+}
+
+func TestCheckConsistencyOfTail(t *testing.T) {
+	bc, key := newProducingChain(t)
+	from := key.PublicKey.Address()
+	to := types.GeneratePrivateKey().PublicKey.Address()
+
+	nonce := uint64(0)
+	for i := int32(0); i < consistencyCheckDepth+10; i++ {
+		txs := []*types.Transaction{}
+		if i%5 == 0 {
+			tx := types.CreateTransaction(nonce, from, to, coins(1), coins(1), nil)
+			assert.Nil(t, tx.Sign(key))
+			txs = append(txs, tx)
+			nonce++
+		}
+		assert.Nil(t, bc.LinkBlock(produceBlock(t, bc, key, txs)))
+	}
+
+	report, err := bc.CheckConsistency()
+	assert.Nil(t, err)
+	assert.True(t, report.OK(), report.String())
+
+	// the startup check only walks the last blocks, the first tx is below them
+	assert.Nil(t, bc.db.DeleteNumberTx(0))
+	report, err = bc.CheckConsistency()
+	assert.Nil(t, err)
+	assert.True(t, report.OK(), report.String())
+
+	report, err = bc.CheckFullConsistency()
+	assert.Nil(t, err)
+	assert.False(t, report.OK())
+
+	// the last tx is in the walked blocks
+	assert.Nil(t, bc.db.DeleteNumberTx(uint32(nonce-1)))
+	report, err = bc.CheckConsistency()
+	assert.Nil(t, err)
+	assert.False(t, report.OK())
 }
 
 func TestNewBlockchain(t *testing.T) {
@@ -61,7 +204,7 @@ func TestNewBlockchain(t *testing.T) {
 	lastBlockHeight, _ := bc.ReadLastBlockHeight()
 
 	assert.NotNil(t, bc.validator)
-	assert.Equal(t, lastBlockHeight, int32(0))
+	assert.Equal(t, int32(0), *lastBlockHeight)
 }
 
 func TestHasBlock(t *testing.T) {
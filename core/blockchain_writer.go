@@ -2,8 +2,8 @@ package core
 
 import (
 	"fmt"
+	"github.com/barreleye-labs/barreleye/barreldb"
 	"github.com/barreleye-labs/barreleye/common"
-	"github.com/barreleye-labs/barreleye/config"
 	"github.com/barreleye-labs/barreleye/core/types"
 )
 
@@ -84,16 +84,20 @@ func (bc *Blockchain) WriteAccountWithAddress(address common.Address, account *t
 	return nil
 }
 
+// RemoveLastBlock unlinks the last block and restores the state from before the block.
+// All changes are committed at once.
 func (bc *Blockchain) RemoveLastBlock() error {
-	if err := bc.removeLastHeader(); err != nil {
+	batch := bc.db.NewBatch()
+
+	if err := removeLastHeader(batch); err != nil {
 		return err
 	}
 
-	if err := bc.removeLastBlockTxs(); err != nil {
+	if err := removeLastBlockTxs(batch); err != nil {
 		return err
 	}
 
-	lastBlock, err := bc.db.SelectLastBlock()
+	lastBlock, err := batch.SelectLastBlock()
 	if err != nil {
 		return err
 	}
@@ -106,21 +110,24 @@ func (bc *Blockchain) RemoveLastBlock() error {
 		return fmt.Errorf("genesis block can not delete")
 	}
 
-	if err = bc.cancelReward(lastBlock.Signer.Address()); err != nil {
+	if err = undoState(batch, lastBlock.Height); err != nil {
 		return err
 	}
 
-	if err = bc.db.DeleteHashBlock(lastBlock.Hash); err != nil {
+	if err = batch.DeleteHashBlock(lastBlock.Hash); err != nil {
+		return err
+	}
+	if err = batch.DeleteHeightBlock(lastBlock.Height); err != nil {
 		return err
 	}
-	if err = bc.db.DeleteHeightBlock(lastBlock.Height); err != nil {
+	if err = batch.DeleteLastBlock(); err != nil {
 		return err
 	}
-	if err = bc.db.DeleteLastBlock(); err != nil {
+	if err = batch.DeleteSnapshot(lastBlock.Height); err != nil {
 		return err
 	}
 
-	prevBlock, err := bc.db.SelectHeightBlock(lastBlock.Height - 1)
+	prevBlock, err := batch.SelectHeightBlock(lastBlock.Height - 1)
 	if err != nil {
 		return err
 	}
@@ -129,15 +136,76 @@ func (bc *Blockchain) RemoveLastBlock() error {
 		return fmt.Errorf("not found previous block for inserting new last block")
 	}
 
-	if err = bc.db.InsertLastBlock(prevBlock); err != nil {
+	if err = batch.InsertLastBlock(prevBlock); err != nil {
+		return err
+	}
+
+	return batch.Commit()
+}
+
+// undoState restores the state keys changed by the block of the height.
+func undoState(batch *barreldb.BarrelDatabase, height int32) error {
+	entries, err := batch.SelectHeightUndo(height)
+	if err != nil {
 		return err
 	}
 
+	if entries == nil {
+		return fmt.Errorf("not found state undo record of block %d", height)
+	}
+
+	for _, entry := range entries {
+		if entry.Value == nil {
+			err = batch.GetTable(entry.Table).Delete(entry.Key)
+		} else {
+			err = batch.GetTable(entry.Table).Put(entry.Key, entry.Value)
+		}
+		if err != nil {
+			return err
+		}
+
+		if entry.Table == barreldb.AddressAccountTableName {
+			address, err := common.NewAddressFromBytes(entry.Key)
+			if err != nil {
+				return err
+			}
+
+			if err = batch.DeleteAddressHeightAccount(address, height); err != nil {
+				return err
+			}
+		}
+	}
+
+	return batch.DeleteHeightUndo(height)
+}
+
+// writeAccountHistory records the accounts changed by the block of the height, so that
+// they can be read as of that block later.
+func writeAccountHistory(batch *barreldb.BarrelDatabase, height int32, undo []barreldb.UndoEntry) error {
+	for _, entry := range undo {
+		if entry.Table != barreldb.AddressAccountTableName {
+			continue
+		}
+
+		address, err := common.NewAddressFromBytes(entry.Key)
+		if err != nil {
+			return err
+		}
+
+		account, err := batch.SelectAddressAccount(address)
+		if err != nil {
+			return err
+		}
+
+		if err = batch.InsertAddressHeightAccount(address, height, account); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (bc *Blockchain) removeLastBlockTxs() error {
-	lastBlock, err := bc.db.SelectLastBlock()
+func removeLastBlockTxs(batch *barreldb.BarrelDatabase) error {
+	lastBlock, err := batch.SelectLastBlock()
 	if err != nil {
 		return err
 	}
@@ -146,7 +214,7 @@ func (bc *Blockchain) removeLastBlockTxs() error {
 		return fmt.Errorf("not found last block for removing txs")
 	}
 
-	lastTxNumber, err := bc.db.SelectLastTxNumber()
+	lastTxNumber, err := batch.SelectLastTxNumber()
 	if err != nil {
 		return err
 	}
@@ -161,42 +229,16 @@ func (bc *Blockchain) removeLastBlockTxs() error {
 	isTxLeft := true
 	for i := 0; i < len(lastBlock.Transactions); i++ {
 		edited = true
-		fromAccount, err := bc.db.SelectAddressAccount(lastBlock.Transactions[i].From)
-		if err != nil {
-			return err
-		}
-
-		if fromAccount == nil {
-			return fmt.Errorf("not found tx from account")
-		}
-
-		toAccount, err := bc.db.SelectAddressAccount(lastBlock.Transactions[i].To)
-		if err != nil {
-			return err
-		}
-
-		if toAccount == nil {
-			return fmt.Errorf("not found tx to account")
-		}
 
-		if err = toAccount.Transfer(fromAccount, lastBlock.Transactions[i].Value); err != nil {
+		if err = batch.DeleteHashTx(lastBlock.Transactions[i].Hash); err != nil {
 			return err
 		}
 
-		fromAccount.Nonce--
-
-		if err = bc.WriteAccountWithAddress(fromAccount.Address, fromAccount); err != nil {
-			return nil
-		}
-		if err = bc.WriteAccountWithAddress(toAccount.Address, toAccount); err != nil {
-			return nil
-		}
-
-		if err = bc.db.DeleteHashTx(lastBlock.Transactions[i].Hash); err != nil {
+		if err = batch.DeleteHashReceipt(lastBlock.Transactions[i].Hash); err != nil {
 			return err
 		}
 
-		if err = bc.db.DeleteNumberTx(targetTxNum); err != nil {
+		if err = batch.DeleteNumberTx(targetTxNum); err != nil {
 			return err
 		}
 
@@ -208,11 +250,11 @@ func (bc *Blockchain) removeLastBlockTxs() error {
 
 	if edited {
 		if isTxLeft {
-			if err = bc.db.UpsertLastTxNumber(targetTxNum); err != nil {
+			if err = batch.UpsertLastTxNumber(targetTxNum); err != nil {
 				return err
 			}
 
-			lastTx, err := bc.db.SelectNumberTx(targetTxNum)
+			lastTx, err := batch.SelectNumberTx(targetTxNum)
 			if err != nil {
 				return err
 			}
@@ -221,16 +263,16 @@ func (bc *Blockchain) removeLastBlockTxs() error {
 				return fmt.Errorf("not found numberTx")
 			}
 
-			if err = bc.db.UpsertLastTx(lastTx); err != nil {
+			if err = batch.UpsertLastTx(lastTx); err != nil {
 				return err
 			}
 
 		} else {
-			if err = bc.db.DeleteLastTxNumber(); err != nil {
+			if err = batch.DeleteLastTxNumber(); err != nil {
 				return err
 			}
 
-			if err = bc.db.DeleteLastTx(); err != nil {
+			if err = batch.DeleteLastTx(); err != nil {
 				return err
 			}
 		}
@@ -238,8 +280,8 @@ func (bc *Blockchain) removeLastBlockTxs() error {
 	return nil
 }
 
-func (bc *Blockchain) removeLastHeader() error {
-	header, err := bc.db.SelectLastHeader()
+func removeLastHeader(batch *barreldb.BarrelDatabase) error {
+	header, err := batch.SelectLastHeader()
 	if err != nil {
 		return err
 	}
@@ -252,17 +294,17 @@ func (bc *Blockchain) removeLastHeader() error {
 		return fmt.Errorf("genesis header can not delete")
 	}
 
-	if err = bc.db.DeleteHashHeader(types.BlockHasher{}.Hash(header)); err != nil {
+	if err = batch.DeleteHashHeader(types.BlockHasher{}.Hash(header)); err != nil {
 		return err
 	}
-	if err = bc.db.DeleteHeightHeader(header.Height); err != nil {
+	if err = batch.DeleteHeightHeader(header.Height); err != nil {
 		return err
 	}
-	if err = bc.db.DeleteLastHeader(); err != nil {
+	if err = batch.DeleteLastHeader(); err != nil {
 		return err
 	}
 
-	prevHeader, err := bc.db.SelectHeightHeader(header.Height - 1)
+	prevHeader, err := batch.SelectHeightHeader(header.Height - 1)
 	if err != nil {
 		return err
 	}
@@ -271,48 +313,39 @@ func (bc *Blockchain) removeLastHeader() error {
 		return fmt.Errorf("not found previous header for inserting new last header")
 	}
 
-	if err = bc.db.InsertLastHeader(prevHeader); err != nil {
+	if err = batch.InsertLastHeader(prevHeader); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (bc *Blockchain) cancelReward(address common.Address) error {
-	account, err := bc.ReadAccountByAddress(address)
+// giveReward mints the reward of the block at height to address and adds it to the
+// total supply, minting only what is left below the maximum supply.
+func (bc *Blockchain) giveReward(state *State, address common.Address, height int32) error {
+	supply, err := state.GetTotalSupply()
 	if err != nil {
 		return err
 	}
 
-	if account == nil {
-		return fmt.Errorf("not found account to cancel reward")
-	}
-
-	if account.Balance < config.BlockReward {
-		return fmt.Errorf("not enough balance to cancel reward")
-	}
-
-	if err = bc.db.DecreaseAccountBalance(address, config.BlockReward); err != nil {
-		return err
+	reward := MintedReward(height, supply)
+	if reward.IsZero() {
+		return nil
 	}
-	return nil
-}
 
-func (bc *Blockchain) GiveReward(address common.Address) error {
-	account, err := bc.ReadAccountByAddress(address)
+	account, err := state.GetOrCreateAccount(address)
 	if err != nil {
 		return err
 	}
 
-	if account == nil {
-		account = types.CreateAccount(address)
-		if err = bc.WriteAccountWithAddress(account.Address, account); err != nil {
-			return err
-		}
+	if err = account.AddBalance(reward); err != nil {
+		return err
 	}
-
-	if err = bc.db.IncreaseAccountBalance(account.Address, config.BlockReward); err != nil {
+	if err = state.SetAccount(account); err != nil {
 		return err
 	}
+
+	supply, _ = supply.Add(reward)
+	state.SetTotalSupply(supply)
 	return nil
 }
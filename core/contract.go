@@ -0,0 +1,202 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"github.com/barreleye-labs/barreleye/core/vm"
+)
+
+// contractState lets contracts run against a State.
+type contractState struct {
+	state *State
+}
+
+func (s contractState) GetStorage(contract common.Address, key [vm.WordSize]byte) ([vm.WordSize]byte, error) {
+	return s.state.GetStorage(contract, key)
+}
+
+func (s contractState) SetStorage(contract common.Address, key [vm.WordSize]byte, value [vm.WordSize]byte) error {
+	s.state.SetStorage(contract, key, value)
+	return nil
+}
+
+func (s contractState) GetBalance(address common.Address) (common.Amount, error) {
+	account, err := s.state.GetOrCreateAccount(address)
+	if err != nil {
+		return common.Amount{}, err
+	}
+	return account.Balance, nil
+}
+
+func (s contractState) Transfer(from common.Address, to common.Address, amount common.Amount) error {
+	fromAccount, err := s.state.GetOrCreateAccount(from)
+	if err != nil {
+		return err
+	}
+
+	if fromAccount.Balance.Lt(amount) {
+		return vm.ErrInsufficientBalance
+	}
+
+	if from.Equal(to) {
+		return nil
+	}
+
+	toAccount, err := s.state.GetOrCreateAccount(to)
+	if err != nil {
+		return err
+	}
+
+	if err = fromAccount.Transfer(toAccount, amount); err != nil {
+		return err
+	}
+
+	if err = s.state.SetAccount(fromAccount); err != nil {
+		return err
+	}
+	return s.state.SetAccount(toAccount)
+}
+
+// deployContractHandler stores the code in the data of the transaction at a new
+// contract address. The value of the transaction is sent to the contract.
+type deployContractHandler struct{}
+
+func (deployContractHandler) Validate(tx *types.Transaction) error {
+	if len(tx.Data) == 0 || len(tx.Data) > config.MaxCodeSize {
+		return types.NewTxError(types.ErrCodeInvalidPayload, fmt.Sprintf("contract code must have 1 to %d bytes", config.MaxCodeSize))
+	}
+	return nil
+}
+
+func (deployContractHandler) Execute(bc *Blockchain, state *State, ctx *TxContext, tx *types.Transaction) error {
+	address := types.CreateContractAddress(tx.From, tx.Nonce)
+
+	code, err := state.GetCode(address)
+	if err != nil {
+		return err
+	}
+
+	if code != nil {
+		return types.NewTxError(types.ErrCodeInvalidPayload, "contract "+address.String()+" already exists")
+	}
+
+	state.SetCode(address, tx.Data)
+	if err = (contractState{state}).Transfer(tx.From, address, tx.Value); err != nil {
+		return err
+	}
+
+	ctx.ContractAddress = address
+
+	_ = bc.logger.Log("msg", "deploy contract", "address", address, "size", len(tx.Data))
+	return nil
+}
+
+// callContractHandler sends the value of the transaction to the contract and runs its
+// code with the data of the transaction as input. The fee buys one gas for every
+// config.GasPrice up to config.MaxTxGas and is paid in full.
+type callContractHandler struct{}
+
+func (callContractHandler) Validate(tx *types.Transaction) error {
+	return nil
+}
+
+func (callContractHandler) Execute(bc *Blockchain, state *State, ctx *TxContext, tx *types.Transaction) error {
+	code, err := state.GetCode(tx.To)
+	if err != nil {
+		return err
+	}
+
+	if code == nil {
+		return types.NewTxError(types.ErrCodeNotContract, tx.To.String()+" is not a contract")
+	}
+
+	db := contractState{state}
+	if err = db.Transfer(tx.From, tx.To, tx.Value); err != nil {
+		return err
+	}
+
+	result, err := vm.Run(code, vm.Context{
+		Caller:    tx.From,
+		Address:   tx.To,
+		Value:     tx.Value,
+		Input:     tx.Data,
+		Height:    ctx.Height,
+		Timestamp: ctx.Timestamp,
+	}, db, gasLimit(tx.Fee))
+	ctx.GasUsed = result.GasUsed
+
+	if err = contractError(err); err != nil {
+		return err
+	}
+
+	_ = bc.logger.Log("msg", "call contract", "address", tx.To, "gasUsed", result.GasUsed)
+	return nil
+}
+
+// gasLimit returns the gas bought by the fee of a contract call, at most config.MaxTxGas.
+func gasLimit(fee common.Amount) uint64 {
+	gas := fee.Div(config.GasPrice)
+	if !gas.IsUint64() || gas.Uint64() > config.MaxTxGas {
+		return config.MaxTxGas
+	}
+	return gas.Uint64()
+}
+
+// TxGas returns the gas the transaction can use. Only contract calls run code.
+func TxGas(tx *types.Transaction) uint64 {
+	if tx.Type != types.TxTypeCallContract {
+		return 0
+	}
+	return gasLimit(tx.Fee)
+}
+
+// contractError turns the errors of the contract into transaction errors. Errors of
+// the state are returned unchanged.
+func contractError(err error) error {
+	if err == nil || errors.Is(err, vm.ErrState) {
+		return err
+	}
+
+	if errors.Is(err, vm.ErrOutOfGas) {
+		return types.NewTxError(types.ErrCodeOutOfGas, err.Error())
+	}
+	return types.NewTxError(types.ErrCodeContractFailed, err.Error())
+}
+
+// CallContract runs a contract on the current state without changing it. It returns
+// nil if there is no contract at the address.
+func (bc *Blockchain) CallContract(address common.Address, caller common.Address, input []byte) (*vm.Result, error) {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	state := NewState(bc.db)
+	code, err := state.GetCode(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if code == nil {
+		return nil, nil
+	}
+
+	lastHeader, err := bc.db.SelectLastHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := vm.Context{
+		Caller:   caller,
+		Address:  address,
+		Input:    input,
+		ReadOnly: true,
+	}
+	if lastHeader != nil {
+		ctx.Height = lastHeader.Height
+		ctx.Timestamp = lastHeader.Timestamp
+	}
+
+	return vm.Run(code, ctx, contractState{state}, config.MaxCallGas)
+}
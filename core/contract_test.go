@@ -0,0 +1,81 @@
+package core
+
+import (
+	"github.com/barreleye-labs/barreleye/core/types"
+	"github.com/barreleye-labs/barreleye/core/vm"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestContractDeployAndCall(t *testing.T) {
+	bc := newMemoryChain(t)
+	state := NewState(bc.db)
+
+	key := types.GeneratePrivateKey()
+	from := key.PublicKey.Address()
+	coinbase := types.GeneratePrivateKey().PublicKey.Address()
+
+	account := types.CreateAccount(from)
+	account.Balance = coins(100)
+	assert.Nil(t, state.SetAccount(account))
+
+	// storage[0] += 1, return storage[0]
+	code := []byte{
+		byte(vm.PUSH), 1, 1, byte(vm.PUSH), 1, 0, byte(vm.SLOAD), byte(vm.ADD),
+		byte(vm.PUSH), 1, 0, byte(vm.SSTORE),
+		byte(vm.PUSH), 1, 0, byte(vm.SLOAD), byte(vm.RETURN),
+	}
+
+	deploy := types.CreateTransaction(0, from, from, coins(10), coins(1), code)
+	deploy.Type = types.TxTypeDeployContract
+	assert.Nil(t, deploy.Sign(key))
+	receipt, err := bc.executeTransaction(state, deploy, 0, BlockContext{Coinbase: coinbase})
+	assert.Nil(t, err)
+	assert.Equal(t, types.ReceiptStatusSuccess, receipt.Status)
+
+	contract := types.CreateContractAddress(from, 0)
+	assert.Equal(t, contract, receipt.ContractAddress)
+
+	stored, err := state.GetCode(contract)
+	assert.Nil(t, err)
+	assert.Equal(t, code, stored)
+
+	contractAccount, err := state.GetAccount(contract)
+	assert.Nil(t, err)
+	assert.Equal(t, coins(10), contractAccount.Balance)
+
+	call := types.CreateTransaction(1, from, contract, coins(0), coins(1), nil)
+	call.Type = types.TxTypeCallContract
+	assert.Nil(t, call.Sign(key))
+	receipt, err = bc.executeTransaction(state, call, 1, BlockContext{Coinbase: coinbase})
+	assert.Nil(t, err)
+	assert.Equal(t, types.ReceiptStatusSuccess, receipt.Status)
+	assert.True(t, receipt.GasUsed > 0)
+
+	var slot [vm.WordSize]byte
+	value, err := state.GetStorage(contract, slot)
+	assert.Nil(t, err)
+	assert.Equal(t, byte(1), value[vm.WordSize-1])
+
+	// a call that runs out of gas is reverted but still pays the fee
+	call = types.CreateTransaction(2, from, contract, coins(0), coins(1), nil)
+	call.Type = types.TxTypeCallContract
+	assert.Nil(t, call.Sign(key))
+	state.SetCode(contract, []byte{byte(vm.PUSH), 1, 0, byte(vm.JUMP)})
+	receipt, err = bc.executeTransaction(state, call, 2, BlockContext{Coinbase: coinbase})
+	assert.Nil(t, err)
+	assert.Equal(t, types.ReceiptStatusFailed, receipt.Status)
+	assert.Equal(t, types.ErrCodeOutOfGas, receipt.ErrorCode)
+
+	sender, err := state.GetAccount(from)
+	assert.Nil(t, err)
+	assert.Equal(t, coins(100-10-3), sender.Balance)
+	assert.Equal(t, uint64(3), sender.Nonce)
+
+	notContract := types.CreateTransaction(3, from, coinbase, coins(0), coins(1), nil)
+	notContract.Type = types.TxTypeCallContract
+	assert.Nil(t, notContract.Sign(key))
+	receipt, err = bc.executeTransaction(state, notContract, 3, BlockContext{Coinbase: coinbase})
+	assert.Nil(t, err)
+	assert.Equal(t, types.ErrCodeNotContract, receipt.ErrorCode)
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"github.com/barreleye-labs/barreleye/config"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func scheduleForks(t *testing.T, forks map[string]int32) {
+	scheduled := config.Forks
+	t.Cleanup(func() { config.Forks = scheduled })
+	config.Forks = forks
+}
+
+func TestForkSchedule(t *testing.T) {
+	scheduleForks(t, map[string]int32{config.ForkFeeBurn: 5, config.ForkChainHash: 10})
+
+	assert.False(t, config.IsForkActive(config.ForkFeeBurn, 4))
+	assert.True(t, config.IsForkActive(config.ForkFeeBurn, 5))
+	assert.Equal(t, uint32(1), config.BlockVersion(9))
+	assert.Equal(t, uint32(2), config.BlockVersion(10))
+
+	assert.Equal(t, []config.ForkActivation{
+		{Name: config.ForkFeeBurn, Height: 5},
+		{Name: config.ForkChainHash, Height: 10},
+	}, config.ForkSchedule())
+
+	g := config.CurrentGenesis()
+	hash, err := g.Hash()
+	assert.Nil(t, err)
+
+	// scheduling a fork keeps the genesis
+	g.Forks[config.ForkFeeBurn] = 6
+	rescheduled, err := g.Hash()
+	assert.Nil(t, err)
+	assert.Equal(t, hash, rescheduled)
+
+	g.Forks["unknown"] = 6
+	assert.NotNil(t, g.Validate())
+}
+
+func TestChainHashFork(t *testing.T) {
+	defer func(chainID uint64) { config.ChainID = chainID }(config.ChainID)
+
+	header := &types.Header{Version: 1, Height: 3}
+	hash := types.BlockHasher{}.Hash(header)
+	config.ChainID++
+	assert.Equal(t, hash, types.BlockHasher{}.Hash(header))
+
+	header.Version = 2
+	hash = types.BlockHasher{}.Hash(header)
+	config.ChainID++
+	assert.NotEqual(t, hash, types.BlockHasher{}.Hash(header))
+}
+
+func TestFeeBurnFork(t *testing.T) {
+	scheduleForks(t, map[string]int32{config.ForkFeeBurn: 2})
+
+	bc := newMemoryChain(t)
+	state := NewState(bc.db)
+
+	key := types.GeneratePrivateKey()
+	from := key.PublicKey.Address()
+	to := types.GeneratePrivateKey().PublicKey.Address()
+	coinbase := types.GeneratePrivateKey().PublicKey.Address()
+
+	account := types.CreateAccount(from)
+	account.Balance = coins(10)
+	assert.Nil(t, state.SetAccount(account))
+	state.SetTotalSupply(coins(10))
+
+	before := types.CreateTransaction(0, from, to, coins(1), coins(3), nil)
+	assert.Nil(t, before.Sign(key))
+	_, err := bc.executeTransaction(state, before, 0, BlockContext{Height: 1, Coinbase: coinbase})
+	assert.Nil(t, err)
+
+	after := types.CreateTransaction(1, from, to, coins(1), coins(3), nil)
+	assert.Nil(t, after.Sign(key))
+	_, err = bc.executeTransaction(state, after, 0, BlockContext{Height: 2, Coinbase: coinbase})
+	assert.Nil(t, err)
+
+	producer, err := state.GetAccount(coinbase)
+	assert.Nil(t, err)
+	assert.Equal(t, coins(3+2), producer.Balance)
+
+	supply, err := state.GetTotalSupply()
+	assert.Nil(t, err)
+	assert.Equal(t, coins(9), supply)
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"time"
+)
+
+// initGenesis links the genesis block into an empty database. A database that already
+// has a chain must have been started from the same genesis file.
+func (bc *Blockchain) initGenesis() error {
+	paramsHash, err := config.CurrentGenesis().Hash()
+	if err != nil {
+		return err
+	}
+
+	genesisHeader, err := bc.ReadHeaderByHeight(0)
+	if err != nil {
+		return err
+	}
+
+	if genesisHeader != nil {
+		if !genesisHeader.PrevBlockHash.Equal(paramsHash) {
+			return fmt.Errorf("database holds a chain of another genesis, remove it or start with its genesis file")
+		}
+		return nil
+	}
+
+	genesisBlock, err := bc.CreateGenesisBlock()
+	if err != nil {
+		return err
+	}
+
+	if err = bc.LinkBlockWithoutValidation(genesisBlock); err != nil {
+		return err
+	}
+
+	_ = bc.logger.Log("msg", "🌞 create genesis block", "hash", genesisBlock.GetHash())
+	return nil
+}
+
+// CreateGenesisBlock builds the genesis block of the configured genesis on top of an
+// empty state. The block is not signed and its previous block hash is the hash of the
+// genesis parameters, so every node of the network builds the same block.
+func (bc *Blockchain) CreateGenesisBlock() (*types.Block, error) {
+	paramsHash, err := config.CurrentGenesis().Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	header := &types.Header{
+		Version:       config.BlockVersion(0),
+		Height:        0,
+		PrevBlockHash: paramsHash,
+		Timestamp:     time.Unix(config.GenesisTimestamp, 0).UnixNano(),
+	}
+
+	b, err := types.NewBlock(header, nil)
+	if err != nil {
+		return nil, err
+	}
+	b.Extra = ""
+
+	if err = bc.FinalizeBlock(b, common.Address{}); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// applyGenesisAlloc credits the prefunded accounts of the genesis and makes their sum
+// the total supply.
+func applyGenesisAlloc(state *State) error {
+	total := common.Amount{}
+	for hexAddress, balance := range config.GenesisAlloc {
+		b, err := hex.DecodeString(hexAddress)
+		if err != nil {
+			return fmt.Errorf("invalid genesis address %s", hexAddress)
+		}
+
+		address, err := common.NewAddressFromBytes(b)
+		if err != nil {
+			return fmt.Errorf("invalid genesis address %s", hexAddress)
+		}
+
+		account, err := state.GetOrCreateAccount(address)
+		if err != nil {
+			return err
+		}
+
+		if err = account.AddBalance(balance); err != nil {
+			return err
+		}
+		if err = state.SetAccount(account); err != nil {
+			return err
+		}
+
+		var overflow bool
+		if total, overflow = total.Add(balance); overflow {
+			return fmt.Errorf("genesis allocations overflow 256 bits")
+		}
+	}
+
+	state.SetTotalSupply(total)
+	return nil
+}
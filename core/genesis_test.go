@@ -0,0 +1,113 @@
+package core
+
+import (
+	"fmt"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenesisBlockIsDeterministic(t *testing.T) {
+	alloc := config.GenesisAlloc
+	defer func() { config.GenesisAlloc = alloc }()
+
+	funded := types.GeneratePrivateKey().PublicKey.Address()
+	config.GenesisAlloc = map[string]common.Amount{funded.String(): coins(1000)}
+
+	first, err := newMemoryChain(t).CreateGenesisBlock()
+	assert.Nil(t, err)
+	second, err := newMemoryChain(t).CreateGenesisBlock()
+	assert.Nil(t, err)
+	assert.Equal(t, first.GetHash(), second.GetHash())
+
+	bc := newMemoryChain(t)
+	assert.Nil(t, bc.initGenesis())
+
+	hash, err := bc.ReadBlockHashByHeight(0)
+	assert.Nil(t, err)
+	assert.Equal(t, first.GetHash(), *hash)
+
+	account, err := bc.ReadAccountByAddress(funded)
+	assert.Nil(t, err)
+	assert.Equal(t, coins(1000), account.Balance)
+
+	supply, err := bc.ReadTotalSupply()
+	assert.Nil(t, err)
+	assert.Equal(t, coins(1000), supply)
+
+	// another genesis gives another genesis block, and the database refuses it
+	config.GenesisAlloc = map[string]common.Amount{funded.String(): coins(1001)}
+	other, err := newMemoryChain(t).CreateGenesisBlock()
+	assert.Nil(t, err)
+	assert.NotEqual(t, first.GetHash(), other.GetHash())
+	assert.NotNil(t, bc.initGenesis())
+}
+
+func TestLoadGenesis(t *testing.T) {
+	g, err := config.LoadGenesis(filepath.Join("..", "genesis.json"))
+	assert.Nil(t, err)
+	assert.Equal(t, config.Validators, g.Validators)
+	assert.Equal(t, 3, len(g.Alloc))
+
+	write := func(content string) string {
+		path := filepath.Join(t.TempDir(), "genesis.json")
+		assert.Nil(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	_, err = config.LoadGenesis(write(`{"chainId": 1, "unknown": true}`))
+	assert.NotNil(t, err)
+
+	_, err = config.LoadGenesis(write(`{
+		"chainId": 7, "timestamp": 1, "symbol": "TST", "decimals": 4,
+		"validators": ["0xF4BCD665C2595FB3253ADE200BB80D7E5DDD9CA2"],
+		"blockTime": "1s", "producerTimeout": "1s",
+		"initialBlockReward": "1", "rewardHalvingInterval": 10, "maxSupply": "100", "minTxFee": "1",
+		"alloc": {"f4bcd665c2595fb3253ade200bb80d7e5ddd9ca2": "101"}
+	}`))
+	assert.NotNil(t, err)
+
+	g, err = config.LoadGenesis(write(`{
+		"chainId": 7, "timestamp": 1, "symbol": "TST", "decimals": 4,
+		"validators": ["0xF4BCD665C2595FB3253ADE200BB80D7E5DDD9CA2"],
+		"blockTime": "1s", "producerTimeout": "1s",
+		"initialBlockReward": "1", "rewardHalvingInterval": 10, "maxSupply": "100", "minTxFee": "1",
+		"alloc": {"0xF4BCD665C2595FB3253ADE200BB80D7E5DDD9CA2": "100"}
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"f4bcd665c2595fb3253ade200bb80d7e5ddd9ca2"}, g.Validators)
+	assert.Equal(t, common.NewAmount(100), g.Alloc["f4bcd665c2595fb3253ade200bb80d7e5ddd9ca2"])
+}
+
+func TestGenesisDecimals(t *testing.T) {
+	defer config.CurrentGenesis().Apply()
+
+	load := func(decimals int) (*config.Genesis, error) {
+		path := filepath.Join(t.TempDir(), "genesis.json")
+		content := fmt.Sprintf(`{
+			"chainId": 7, "timestamp": 1, "symbol": "TST", "decimals": %d,
+			"validators": ["f4bcd665c2595fb3253ade200bb80d7e5ddd9ca2"],
+			"blockTime": "1s", "producerTimeout": "1s",
+			"initialBlockReward": "1", "rewardHalvingInterval": 10, "maxSupply": "100", "minTxFee": "1"
+		}`, decimals)
+		assert.Nil(t, os.WriteFile(path, []byte(content), 0o644))
+		return config.LoadGenesis(path)
+	}
+
+	// amounts in whole coins would overflow or the gas price would be 0
+	for _, decimals := range []int{0, config.MinDecimals - 1, config.MaxDecimals + 1, common.MaxDecimals} {
+		_, err := load(decimals)
+		assert.NotNil(t, err, decimals)
+	}
+
+	for _, decimals := range []int{config.MinDecimals, config.MaxDecimals} {
+		g, err := load(decimals)
+		assert.Nil(t, err, decimals)
+		g.Apply()
+		assert.False(t, config.GasPrice.IsZero(), decimals)
+	}
+}
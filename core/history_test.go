@@ -0,0 +1,51 @@
+package core
+
+import (
+	"github.com/barreleye-labs/barreleye/core/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAccountHistory(t *testing.T) {
+	bc := newMemoryChain(t)
+	address := types.GeneratePrivateKey().PublicKey.Address()
+
+	commit := func(height int32, balance uint64) {
+		state := NewState(bc.db)
+		account := types.CreateAccount(address)
+		account.Balance = coins(balance)
+		assert.Nil(t, state.SetAccount(account))
+
+		batch := bc.db.NewBatch()
+		undo, err := state.Commit(batch)
+		assert.Nil(t, err)
+		assert.Nil(t, batch.InsertHeightUndo(height, undo))
+		assert.Nil(t, writeAccountHistory(batch, height, undo))
+		assert.Nil(t, batch.Commit())
+	}
+
+	commit(1, 10)
+	commit(3, 30)
+
+	account, err := bc.db.SelectAddressAccountAtHeight(address, 0)
+	assert.Nil(t, err)
+	assert.Nil(t, account)
+
+	for height, balance := range map[int32]uint64{1: 10, 2: 10, 3: 30, 100: 30} {
+		account, err = bc.db.SelectAddressAccountAtHeight(address, height)
+		assert.Nil(t, err)
+		assert.Equal(t, coins(balance), account.Balance)
+	}
+
+	batch := bc.db.NewBatch()
+	assert.Nil(t, undoState(batch, 3))
+	assert.Nil(t, batch.Commit())
+
+	account, err = bc.db.SelectAddressAccountAtHeight(address, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, coins(10), account.Balance)
+
+	current, err := bc.ReadAccountByAddress(address)
+	assert.Nil(t, err)
+	assert.Equal(t, coins(10), current.Balance)
+}
@@ -0,0 +1,621 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/barreleye-labs/barreleye/barreldb"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"strconv"
+	"strings"
+)
+
+// blockHeightOf returns the height of the block an entry of a derived table belongs to.
+type blockHeightOf func(key []byte, value []byte) (int32, error)
+
+// derivedTables hold data that can be rebuilt by relinking the blocks of the height-block
+// table. Each table maps to the height of the block an entry belongs to, or to nil for
+// the pointers to the last block and transaction.
+var derivedTables = map[string]blockHeightOf{
+	barreldb.LastBlockTableName:            nil,
+	barreldb.HashHeaderTableName:           headerHeight,
+	barreldb.HeightHeaderTableName:         keyHeight,
+	barreldb.LastHeaderTableName:           nil,
+	barreldb.HashTxTableName:               txHeight,
+	barreldb.NumberTxTableName:             txHeight,
+	barreldb.LastTxTableName:               nil,
+	barreldb.LastTxNumberTableName:         nil,
+	barreldb.HashReceiptTableName:          receiptHeight,
+	barreldb.HeightUndoTableName:           keyHeight,
+	barreldb.AddressHeightAccountTableName: historyHeight,
+}
+
+func keyHeight(key []byte, _ []byte) (int32, error) {
+	height, err := strconv.Atoi(string(key))
+	return int32(height), err
+}
+
+func headerHeight(_ []byte, value []byte) (int32, error) {
+	header := new(types.Header)
+	if err := header.Decode(types.NewGobHeaderDecoder(bytes.NewBuffer(value))); err != nil {
+		return 0, err
+	}
+	return header.Height, nil
+}
+
+func txHeight(_ []byte, value []byte) (int32, error) {
+	tx := new(types.Transaction)
+	if err := types.NewGobTxDecoder(bytes.NewBuffer(value)).Decode(tx); err != nil {
+		return 0, err
+	}
+	return tx.BlockHeight, nil
+}
+
+func receiptHeight(_ []byte, value []byte) (int32, error) {
+	receipt := new(types.Receipt)
+	if err := types.NewGobReceiptDecoder(bytes.NewBuffer(value)).Decode(receipt); err != nil {
+		return 0, err
+	}
+	return receipt.BlockHeight, nil
+}
+
+func historyHeight(key []byte, _ []byte) (int32, error) {
+	return int32(binary.BigEndian.Uint32(key[common.AddressLength:])), nil
+}
+
+// ConsistencyReport is the result of the startup check of the database.
+// Blocks up to ConsistentHeight are stored and linked to each other, the
+// problems describe everything else that does not agree with them.
+type ConsistencyReport struct {
+	LastHeight       int32
+	ConsistentHeight int32
+	Problems         []string
+}
+
+func (r *ConsistencyReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *ConsistencyReport) String() string {
+	lines := []string{
+		fmt.Sprintf("last block height: %d, consistent height: %d", r.LastHeight, r.ConsistentHeight),
+	}
+	for _, problem := range r.Problems {
+		lines = append(lines, " - "+problem)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *ConsistencyReport) addProblem(format string, a ...any) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, a...))
+}
+
+// consistencyCheckDepth is the number of blocks below the last one that the startup
+// check walks. An interrupted node leaves its damage at the end of the chain.
+const consistencyCheckDepth = int32(128)
+
+// CheckConsistency verifies that the last block, header and tx pointers, the height and
+// hash indexes of the last consistencyCheckDepth blocks and the stored state root all
+// agree with the blocks stored by height. It is cheap enough to run on every startup.
+func (bc *Blockchain) CheckConsistency() (*ConsistencyReport, error) {
+	return bc.checkConsistency(false)
+}
+
+// CheckFullConsistency walks every block from the genesis block and recomputes the state
+// root from every account instead. It is run before a repair.
+func (bc *Blockchain) CheckFullConsistency() (*ConsistencyReport, error) {
+	return bc.checkConsistency(true)
+}
+
+func (bc *Blockchain) checkConsistency(full bool) (*ConsistencyReport, error) {
+	report := &ConsistencyReport{LastHeight: -1, ConsistentHeight: -1}
+
+	lastBlock, err := bc.db.SelectLastBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	lastHeader, err := bc.db.SelectLastHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	if lastBlock != nil {
+		report.LastHeight = lastBlock.Height
+	}
+
+	if lastHeader == nil && lastBlock != nil {
+		report.addProblem("last header is missing")
+	}
+	if lastHeader != nil && lastBlock == nil {
+		report.addProblem("last block is missing")
+	}
+	if lastHeader != nil && lastBlock != nil && !(types.BlockHasher{}).Hash(lastHeader).Equal(lastBlock.GetHash()) {
+		report.addProblem("last header (height %d) does not match last block (height %d)", lastHeader.Height, lastBlock.Height)
+	}
+
+	mark, err := bc.db.SelectPruneMark()
+	if err != nil {
+		return nil, err
+	}
+	if mark == nil {
+		mark = &barreldb.PruneMark{}
+	}
+
+	start := int32(0)
+	if !full && report.LastHeight-consistencyCheckDepth > 0 {
+		start = report.LastHeight - consistencyCheckDepth
+	}
+
+	var prevHash common.Hash
+	txNumber := mark.TxCount
+	var lastTx *types.Transaction
+	if start > 0 {
+		if prevHash, txNumber, lastTx, err = bc.tailStart(start, mark); err != nil {
+			return nil, err
+		}
+	}
+
+	for height := start; ; height++ {
+		if height < mark.Height {
+			// only the header of a pruned block is left to check
+			header, err := bc.db.SelectHeightHeader(height)
+			if err != nil {
+				return nil, err
+			}
+
+			if header == nil {
+				report.addProblem("header %d of a pruned block is missing", height)
+				break
+			}
+
+			if height > 0 && !header.PrevBlockHash.Equal(prevHash) {
+				report.addProblem("block %d is not linked to block %d", height, height-1)
+				break
+			}
+
+			report.ConsistentHeight = height
+			prevHash = (types.BlockHasher{}).Hash(header)
+			continue
+		}
+
+		block, err := bc.db.SelectHeightBlock(height)
+		if err != nil {
+			return nil, err
+		}
+
+		if block == nil {
+			break
+		}
+
+		if height > 0 && !block.PrevBlockHash.Equal(prevHash) {
+			report.addProblem("block %d is not linked to block %d", height, height-1)
+			break
+		}
+
+		report.ConsistentHeight = height
+		prevHash = block.GetHash()
+
+		if err = bc.checkBlockIndexes(report, block); err != nil {
+			return nil, err
+		}
+
+		for _, tx := range block.Transactions {
+			numberTx, err := bc.db.SelectNumberTx(txNumber)
+			if err != nil {
+				return nil, err
+			}
+			if numberTx == nil || !numberTx.GetHash().Equal(tx.GetHash()) {
+				report.addProblem("tx number %d does not match tx %s of block %d", txNumber, tx.GetHash(), height)
+			}
+
+			hashTx, err := bc.db.SelectHashTx(tx.GetHash())
+			if err != nil {
+				return nil, err
+			}
+			if hashTx == nil {
+				report.addProblem("tx %s of block %d is missing", tx.GetHash(), height)
+			}
+
+			receipt, err := bc.db.SelectHashReceipt(tx.GetHash())
+			if err != nil {
+				return nil, err
+			}
+			if receipt == nil {
+				report.addProblem("receipt of tx %s of block %d is missing", tx.GetHash(), height)
+			}
+
+			lastTx = tx
+			txNumber++
+		}
+	}
+
+	if report.ConsistentHeight != report.LastHeight {
+		report.addProblem("last block height %d does not match the stored blocks up to height %d", report.LastHeight, report.ConsistentHeight)
+	}
+
+	if err = bc.checkTxPointers(report, lastTx, txNumber); err != nil {
+		return nil, err
+	}
+
+	if report.ConsistentHeight >= 0 {
+		header, err := bc.db.SelectHeightHeader(report.ConsistentHeight)
+		if err != nil {
+			return nil, err
+		}
+
+		trieRoot, err := NewState(bc.db).Root()
+		if err != nil {
+			return nil, err
+		}
+
+		if header != nil && !header.StateRoot.Equal(trieRoot) {
+			report.addProblem("state trie does not match the state root of block %d", report.ConsistentHeight)
+		}
+
+		if full {
+			stateRoot, err := NewState(bc.db).fullRoot()
+			if err != nil {
+				return nil, err
+			}
+
+			if header != nil && !header.StateRoot.Equal(stateRoot) {
+				report.addProblem("account state does not match the state root of block %d", report.ConsistentHeight)
+			}
+		}
+
+		// locks of a database written before the lock indexes would never be released.
+		hasLocks, err := hasEntries(bc.db, barreldb.HashLockTableName)
+		if err != nil {
+			return nil, err
+		}
+		heightIndexed, err := hasEntries(bc.db, barreldb.HeightLockTableName)
+		if err != nil {
+			return nil, err
+		}
+		timeIndexed, err := hasEntries(bc.db, barreldb.TimeLockTableName)
+		if err != nil {
+			return nil, err
+		}
+
+		if hasLocks && !heightIndexed && !timeIndexed {
+			report.addProblem("locks are not indexed by their unlock point")
+		}
+	}
+
+	return report, nil
+}
+
+// tailStart returns the hash of the block before start, the number of the first
+// transaction from start on and the last transaction, which is taken as stored when the
+// blocks from start on have none.
+func (bc *Blockchain) tailStart(start int32, mark *barreldb.PruneMark) (common.Hash, uint32, *types.Transaction, error) {
+	prevHash, err := bc.db.SelectHeightBlockHash(start - 1)
+	if err != nil {
+		return common.Hash{}, 0, nil, err
+	}
+	if prevHash == nil {
+		prevHash = &common.Hash{}
+	}
+
+	lastTx, err := bc.db.SelectLastTx()
+	if err != nil {
+		return common.Hash{}, 0, nil, err
+	}
+
+	if start <= mark.Height {
+		return *prevHash, mark.TxCount, lastTx, nil
+	}
+
+	lastTxNumber, err := bc.db.SelectLastTxNumber()
+	if err != nil {
+		return common.Hash{}, 0, nil, err
+	}
+
+	txCount := uint32(0)
+	if lastTxNumber != nil {
+		txCount = *lastTxNumber + 1
+	}
+
+	// the transactions of the tail are counted back from the last tx number, a wrong
+	// number shows up as transactions that do not match their numbers.
+	for height := start; ; height++ {
+		block, err := bc.db.SelectHeightBlock(height)
+		if err != nil {
+			return common.Hash{}, 0, nil, err
+		}
+		if block == nil {
+			break
+		}
+
+		if uint32(len(block.Transactions)) > txCount {
+			txCount = 0
+			break
+		}
+		txCount -= uint32(len(block.Transactions))
+	}
+	return *prevHash, txCount, lastTx, nil
+}
+
+func hasEntries(db *barreldb.BarrelDatabase, table string) (bool, error) {
+	found := false
+	err := db.GetTable(table).IterateRange(nil, nil, func(key []byte, value []byte) error {
+		found = true
+		return barreldb.ErrStopIteration
+	})
+	return found, err
+}
+
+func (bc *Blockchain) checkBlockIndexes(report *ConsistencyReport, block *types.Block) error {
+	hash := block.GetHash()
+
+	header, err := bc.db.SelectHeightHeader(block.Height)
+	if err != nil {
+		return err
+	}
+	if header == nil || !(types.BlockHasher{}).Hash(header).Equal(hash) {
+		report.addProblem("header %d does not match block %d", block.Height, block.Height)
+	}
+
+	hashBlock, err := bc.db.SelectHashBlock(hash)
+	if err != nil {
+		return err
+	}
+	if hashBlock == nil {
+		report.addProblem("block %d is missing in the hash index", block.Height)
+	}
+
+	hashHeader, err := bc.db.SelectHashHeader(hash)
+	if err != nil {
+		return err
+	}
+	if hashHeader == nil {
+		report.addProblem("header %d is missing in the hash index", block.Height)
+	}
+
+	undo, err := bc.db.SelectHeightUndo(block.Height)
+	if err != nil {
+		return err
+	}
+	if undo == nil {
+		report.addProblem("state undo record of block %d is missing", block.Height)
+	}
+
+	for _, entry := range undo {
+		if entry.Table != barreldb.AddressAccountTableName {
+			continue
+		}
+
+		address, err := common.NewAddressFromBytes(entry.Key)
+		if err != nil {
+			return err
+		}
+
+		ok, err := bc.db.HasAddressHeightAccount(address, block.Height)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			report.addProblem("account history of block %d is missing", block.Height)
+			break
+		}
+	}
+	return nil
+}
+
+func (bc *Blockchain) checkTxPointers(report *ConsistencyReport, lastTx *types.Transaction, txCount uint32) error {
+	lastTxNumber, err := bc.db.SelectLastTxNumber()
+	if err != nil {
+		return err
+	}
+
+	storedLastTx, err := bc.db.SelectLastTx()
+	if err != nil {
+		return err
+	}
+
+	if txCount == 0 {
+		if lastTxNumber != nil || storedLastTx != nil {
+			report.addProblem("last tx is set but the blocks have no transactions")
+		}
+		return nil
+	}
+
+	if lastTxNumber == nil || *lastTxNumber != txCount-1 {
+		report.addProblem("last tx number does not match the %d transactions in the blocks", txCount)
+	}
+
+	if storedLastTx == nil || !storedLastTx.GetHash().Equal(lastTx.GetHash()) {
+		report.addProblem("last tx does not match tx %s", lastTx.GetHash())
+	}
+	return nil
+}
+
+// Repair rebuilds every derived table by relinking the blocks up to the consistent
+// height of the report. Relinking stops at the first block whose state root does
+// not match, so the chain is rewound to the last block that can be reproduced.
+// A pruned database no longer has its old blocks, so it starts over from its latest
+// snapshot instead and only rebuilds the data of the blocks after it.
+func (bc *Blockchain) Repair(report *ConsistencyReport) error {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	mark, err := bc.db.SelectPruneMark()
+	if err != nil {
+		return err
+	}
+
+	base := int32(-1)
+	var snapshot *barreldb.Snapshot
+	if mark != nil && mark.Height > 0 {
+		if snapshot, err = bc.repairSnapshot(mark, report.ConsistentHeight); err != nil {
+			return err
+		}
+		base = snapshot.Height
+	}
+
+	batch := bc.db.NewBatch()
+
+	err = bc.db.GetTable(barreldb.HeightBlockTableName).Iterate(func(key []byte, value []byte) error {
+		height, err := strconv.Atoi(string(key))
+		if err != nil {
+			return err
+		}
+
+		if int32(height) > report.ConsistentHeight {
+			return deleteBlockBody(bc.db, batch, int32(height))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for table, heightOf := range derivedTables {
+		err = bc.db.GetTable(table).Iterate(func(key []byte, value []byte) error {
+			if heightOf != nil {
+				height, err := heightOf(key, value)
+				if err != nil {
+					return err
+				}
+				if height <= base {
+					return nil
+				}
+			}
+			return batch.GetTable(table).Delete(append([]byte{}, key...))
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for table := range stateTables {
+		err = bc.db.GetTable(table).Iterate(func(key []byte, value []byte) error {
+			return batch.GetTable(table).Delete(append([]byte{}, key...))
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// the state trie is built again with the state.
+	err = bc.db.GetTable(barreldb.StateTrieTableName).Iterate(func(key []byte, value []byte) error {
+		return batch.GetTable(barreldb.StateTrieTableName).Delete(append([]byte{}, key...))
+	})
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := bc.db.SelectSnapshots()
+	if err != nil {
+		return err
+	}
+	for _, stale := range snapshots {
+		if stale.Height > base {
+			if err = batch.DeleteSnapshot(stale.Height); err != nil {
+				return err
+			}
+		}
+	}
+
+	if snapshot != nil {
+		if err = restoreSnapshot(bc.db, batch, snapshot); err != nil {
+			return err
+		}
+	}
+
+	if err = batch.Commit(); err != nil {
+		return err
+	}
+
+	repairedHeight := report.ConsistentHeight
+	for height := base + 1; height <= report.ConsistentHeight; height++ {
+		block, err := bc.db.SelectHeightBlock(height)
+		if err != nil {
+			return err
+		}
+
+		if err = bc.LinkBlockWithoutValidation(block); err != nil {
+			if !errors.Is(err, common.ErrStateRootMismatch) {
+				return err
+			}
+
+			_ = bc.logger.Log("msg", "rewind chain", "height", height-1, "reason", err)
+			repairedHeight = height - 1
+
+			batch = bc.db.NewBatch()
+			for h := height; h <= report.ConsistentHeight; h++ {
+				if err = deleteBlockBody(bc.db, batch, h); err != nil {
+					return err
+				}
+			}
+			if err = batch.Commit(); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	_ = bc.logger.Log("msg", "🔧 database repaired", "height", repairedHeight)
+	return nil
+}
+
+// deleteBlockBody deletes the block of the height from the height and hash indexes.
+func deleteBlockBody(db *barreldb.BarrelDatabase, batch *barreldb.BarrelDatabase, height int32) error {
+	hash, err := db.SelectHeightBlockHash(height)
+	if err != nil {
+		return err
+	}
+
+	if hash != nil {
+		if err = batch.DeleteHashBlock(*hash); err != nil {
+			return err
+		}
+	}
+	return batch.DeleteHeightBlock(height)
+}
+
+// restoreSnapshot writes the state of the snapshot and points the last block and
+// transaction at the block of the snapshot.
+func restoreSnapshot(db *barreldb.BarrelDatabase, batch *barreldb.BarrelDatabase, snapshot *barreldb.Snapshot) error {
+	err := db.IterateSnapshotState(snapshot.Height, func(table string, key []byte, value []byte) error {
+		return batch.GetTable(table).Put(append([]byte{}, key...), append([]byte{}, value...))
+	})
+	if err != nil {
+		return err
+	}
+
+	block, err := db.SelectHeightBlock(snapshot.Height)
+	if err != nil {
+		return err
+	}
+	if block == nil {
+		return fmt.Errorf("not found block %d of the snapshot", snapshot.Height)
+	}
+
+	if err = batch.InsertLastBlock(block); err != nil {
+		return err
+	}
+	if err = batch.InsertLastHeader(block.Header); err != nil {
+		return err
+	}
+
+	if snapshot.TxCount == 0 {
+		return nil
+	}
+
+	if err = batch.UpsertLastTxNumber(snapshot.TxCount - 1); err != nil {
+		return err
+	}
+
+	lastTx, err := db.SelectNumberTx(snapshot.TxCount - 1)
+	if err != nil {
+		return err
+	}
+	if lastTx != nil {
+		return batch.UpsertLastTx(lastTx)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"encoding/binary"
+	"github.com/barreleye-labs/barreleye/barreldb"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/core/types"
+)
+
+// lockTransfer moves the value of a time-locked transfer from the spendable balance of
+// the sender to the locked balance of the receiver until the lock is released.
+func lockTransfer(state *State, tx *types.Transaction) error {
+	fromAccount, err := state.GetOrCreateAccount(tx.From)
+	if err != nil {
+		return err
+	}
+
+	toAccount, err := state.GetOrCreateAccount(tx.To)
+	if err != nil {
+		return err
+	}
+
+	if err = fromAccount.SubBalance(tx.Value); err != nil {
+		return err
+	}
+	if err = toAccount.AddLocked(tx.Value); err != nil {
+		return err
+	}
+
+	if err = state.SetAccount(fromAccount); err != nil {
+		return err
+	}
+	if err = state.SetAccount(toAccount); err != nil {
+		return err
+	}
+
+	lock := &types.Lock{
+		Address:      tx.To,
+		Amount:       tx.Value,
+		UnlockHeight: tx.UnlockHeight,
+		UnlockTime:   tx.UnlockTime,
+	}
+	if err = state.SetLock(tx.GetHash(), lock); err != nil {
+		return err
+	}
+
+	state.put(barreldb.HeightLockTableName, lockHeightKey(lock.UnlockHeight, tx.GetHash()), tx.GetHash().ToSlice())
+	return nil
+}
+
+// lockHeightKey and lockTimeKey order the lock indexes by the unlock point. The hash
+// of the lock keeps the keys of locks with the same unlock point apart.
+func lockHeightKey(height int32, hash common.Hash) []byte {
+	return append(binary.BigEndian.AppendUint32(nil, uint32(height)), hash.ToSlice()...)
+}
+
+func lockTimeKey(time int64, hash common.Hash) []byte {
+	return append(binary.BigEndian.AppendUint64(nil, uint64(time)), hash.ToSlice()...)
+}
+
+// releaseLocks makes the locked amounts that are due at the block spendable. A lock
+// waits in the height index until its unlock height is reached and then in the time
+// index until its unlock time is reached as well, so that only due index entries are read.
+func (bc *Blockchain) releaseLocks(state *State, height int32, timestamp int64) error {
+	timeLimit := binary.BigEndian.AppendUint64(nil, uint64(timestamp)+1)
+	err := state.iterateRange(barreldb.TimeLockTableName, nil, timeLimit, func(key []byte, value []byte) error {
+		state.put(barreldb.TimeLockTableName, key, nil)
+
+		hash, err := common.HashFromBytes(value)
+		if err != nil {
+			return err
+		}
+		return bc.releaseLock(state, hash)
+	})
+	if err != nil {
+		return err
+	}
+
+	heightLimit := binary.BigEndian.AppendUint32(nil, uint32(height)+1)
+	return state.iterateRange(barreldb.HeightLockTableName, nil, heightLimit, func(key []byte, value []byte) error {
+		state.put(barreldb.HeightLockTableName, key, nil)
+
+		hash, err := common.HashFromBytes(value)
+		if err != nil {
+			return err
+		}
+
+		lock, err := state.GetLock(hash)
+		if err != nil || lock == nil {
+			return err
+		}
+
+		if lock.IsDue(height, timestamp) {
+			return bc.releaseLock(state, hash)
+		}
+		state.put(barreldb.TimeLockTableName, lockTimeKey(lock.UnlockTime, hash), value)
+		return nil
+	})
+}
+
+func (bc *Blockchain) releaseLock(state *State, hash common.Hash) error {
+	lock, err := state.GetLock(hash)
+	if err != nil || lock == nil {
+		return err
+	}
+
+	account, err := state.GetOrCreateAccount(lock.Address)
+	if err != nil {
+		return err
+	}
+
+	if err = account.SubLocked(lock.Amount); err != nil {
+		return err
+	}
+	if err = account.AddBalance(lock.Amount); err != nil {
+		return err
+	}
+	if err = state.SetAccount(account); err != nil {
+		return err
+	}
+	state.DeleteLock(hash)
+
+	_ = bc.logger.Log("msg", "release lock", "address", lock.Address, "amount", lock.Amount, "tx", hash)
+	return nil
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"github.com/barreleye-labs/barreleye/barreldb"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLockedTransferRelease(t *testing.T) {
+	bc := newMemoryChain(t)
+	state := NewState(bc.db)
+
+	key := types.GeneratePrivateKey()
+	from := key.PublicKey.Address()
+	to := types.GeneratePrivateKey().PublicKey.Address()
+	coinbase := types.GeneratePrivateKey().PublicKey.Address()
+
+	account := types.CreateAccount(from)
+	account.Balance = coins(100)
+	assert.Nil(t, state.SetAccount(account))
+
+	tx := types.CreateTransaction(0, from, to, coins(40), coins(1), nil)
+	tx.UnlockHeight = 5
+	assert.Nil(t, tx.Sign(key))
+	receipt, err := bc.executeTransaction(state, tx, 0, BlockContext{Coinbase: coinbase})
+	assert.Nil(t, err)
+	assert.Equal(t, types.ReceiptStatusSuccess, receipt.Status)
+
+	receiver, err := state.GetAccount(to)
+	assert.Nil(t, err)
+	assert.Equal(t, coins(0), receiver.Balance)
+	assert.Equal(t, coins(40), receiver.Locked)
+
+	assert.Nil(t, bc.releaseLocks(state, 4, 0))
+	receiver, err = state.GetAccount(to)
+	assert.Nil(t, err)
+	assert.Equal(t, coins(40), receiver.Locked)
+
+	assert.Nil(t, bc.releaseLocks(state, 5, 0))
+	receiver, err = state.GetAccount(to)
+	assert.Nil(t, err)
+	assert.Equal(t, coins(40), receiver.Balance)
+	assert.Equal(t, coins(0), receiver.Locked)
+
+	count := 0
+	assert.Nil(t, state.Locks(func(_ common.Hash, _ *types.Lock) error {
+		count++
+		return nil
+	}))
+	assert.Equal(t, 0, count)
+}
+
+func TestLockIndexRelease(t *testing.T) {
+	bc := newMemoryChain(t)
+	state := NewState(bc.db)
+
+	key := types.GeneratePrivateKey()
+	from := key.PublicKey.Address()
+	to := types.GeneratePrivateKey().PublicKey.Address()
+
+	account := types.CreateAccount(from)
+	account.Balance = coins(100)
+	assert.Nil(t, state.SetAccount(account))
+
+	// the first lock waits for a time, the second for a height and a time
+	timeLocked := types.CreateTransaction(0, from, to, coins(10), coins(1), nil)
+	timeLocked.UnlockTime = 1000
+	bothLocked := types.CreateTransaction(1, from, to, coins(20), coins(1), nil)
+	bothLocked.UnlockHeight = 3
+	bothLocked.UnlockTime = 2000
+
+	for _, tx := range []*types.Transaction{timeLocked, bothLocked} {
+		assert.Nil(t, tx.Sign(key))
+		receipt, err := bc.executeTransaction(state, tx, 0, BlockContext{})
+		assert.Nil(t, err)
+		assert.Equal(t, types.ReceiptStatusSuccess, receipt.Status)
+	}
+
+	indexed := func(table string) int {
+		count := 0
+		assert.Nil(t, state.iterate(table, func(_ []byte, _ []byte) error {
+			count++
+			return nil
+		}))
+		return count
+	}
+	locked := func() common.Amount {
+		receiver, err := state.GetAccount(to)
+		assert.Nil(t, err)
+		return receiver.Locked
+	}
+
+	assert.Equal(t, 2, indexed(barreldb.HeightLockTableName))
+
+	// the time lock moves to the time index, the height lock is not due yet
+	assert.Nil(t, bc.releaseLocks(state, 1, 500))
+	assert.Equal(t, 1, indexed(barreldb.HeightLockTableName))
+	assert.Equal(t, 1, indexed(barreldb.TimeLockTableName))
+	assert.Equal(t, coins(30), locked())
+
+	assert.Nil(t, bc.releaseLocks(state, 2, 1000))
+	assert.Equal(t, coins(20), locked())
+
+	// the unlock height is reached before the unlock time
+	assert.Nil(t, bc.releaseLocks(state, 3, 1500))
+	assert.Equal(t, 0, indexed(barreldb.HeightLockTableName))
+	assert.Equal(t, 1, indexed(barreldb.TimeLockTableName))
+	assert.Equal(t, coins(20), locked())
+
+	assert.Nil(t, bc.releaseLocks(state, 4, 2000))
+	assert.Equal(t, 0, indexed(barreldb.TimeLockTableName))
+	assert.Equal(t, coins(0), locked())
+
+	receiver, err := state.GetAccount(to)
+	assert.Nil(t, err)
+	assert.Equal(t, coins(30), receiver.Balance)
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"github.com/barreleye-labs/barreleye/core/types"
+)
+
+// AuthorizeSender checks that the signers of the transaction may send from tx.From. A
+// plain account must have signed the transaction itself, a multisig account, whose
+// policy is given, needs enough of its signers.
+func AuthorizeSender(policy *types.MultisigPolicy, tx *types.Transaction) error {
+	signers, err := tx.SignerAddresses()
+	if err != nil {
+		return types.NewTxError(types.ErrCodeUnauthorized, err.Error())
+	}
+
+	if policy == nil {
+		if !signers[0].Equal(tx.From) {
+			return types.NewTxError(types.ErrCodeUnauthorized, "tx is not signed by its sender")
+		}
+		return nil
+	}
+
+	if !policy.IsAuthorized(signers) {
+		return types.NewTxError(types.ErrCodeUnauthorized, "not enough signers of the multisig account")
+	}
+	return nil
+}
+
+// createMultisigHandler stores the policy of a new multisig account. The address of
+// the account is derived from the policy, the receiver of the transaction is ignored.
+type createMultisigHandler struct{}
+
+func (createMultisigHandler) Validate(tx *types.Transaction) error {
+	if !tx.Value.IsZero() {
+		return types.NewTxError(types.ErrCodeInvalidPayload, "create multisig transactions can not transfer value")
+	}
+
+	if _, err := types.DecodeCreateMultisigPayload(tx.Data); err != nil {
+		return types.NewTxError(types.ErrCodeInvalidPayload, "invalid create multisig payload: "+err.Error())
+	}
+	return nil
+}
+
+func (createMultisigHandler) Execute(bc *Blockchain, state *State, ctx *TxContext, tx *types.Transaction) error {
+	policy, err := types.DecodeCreateMultisigPayload(tx.Data)
+	if err != nil {
+		return err
+	}
+
+	address := policy.Address()
+	stored, err := state.GetMultisigPolicy(address)
+	if err != nil {
+		return err
+	}
+
+	if stored != nil {
+		return types.NewTxError(types.ErrCodeMultisigExists, "multisig account "+address.String()+" already exists")
+	}
+
+	if err = state.SetMultisigPolicy(policy); err != nil {
+		return err
+	}
+
+	_ = bc.logger.Log("msg", "create multisig", "address", address, "threshold", policy.Threshold, "signers", len(policy.Signers))
+	return nil
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"encoding/json"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMultisigTwoOfThree(t *testing.T) {
+	bc := newMemoryChain(t)
+	state := NewState(bc.db)
+	coinbase := types.GeneratePrivateKey().PublicKey.Address()
+	receiver := types.GeneratePrivateKey().PublicKey.Address()
+
+	officers := []*types.PrivateKey{types.GeneratePrivateKey(), types.GeneratePrivateKey(), types.GeneratePrivateKey()}
+	signers := []string{}
+	addresses := []common.Address{}
+	for _, officer := range officers {
+		signers = append(signers, officer.PublicKey.Address().String())
+		addresses = append(addresses, officer.PublicKey.Address())
+	}
+
+	account := types.CreateAccount(addresses[0])
+	account.Balance = coins(10)
+	assert.Nil(t, state.SetAccount(account))
+
+	data, err := json.Marshal(types.CreateMultisigPayload{Threshold: 2, Signers: signers})
+	assert.Nil(t, err)
+	create := types.CreateTransaction(0, addresses[0], addresses[0], coins(0), coins(1), data)
+	create.Type = types.TxTypeCreateMultisig
+	assert.Nil(t, create.Sign(officers[0]))
+
+	receipt, err := bc.executeTransaction(state, create, 0, BlockContext{Coinbase: coinbase})
+	assert.Nil(t, err)
+	assert.Equal(t, types.ReceiptStatusSuccess, receipt.Status)
+
+	policy, err := types.NewMultisigPolicy(2, []common.Address{addresses[2], addresses[1], addresses[0]})
+	assert.Nil(t, err)
+	treasury := policy.Address()
+
+	stored, err := state.GetMultisigPolicy(treasury)
+	assert.Nil(t, err)
+	assert.Equal(t, policy, stored)
+
+	funding := types.CreateAccount(treasury)
+	funding.Balance = coins(100)
+	assert.Nil(t, state.SetAccount(funding))
+
+	spend := types.CreateTransaction(0, treasury, receiver, coins(50), coins(1), nil)
+	assert.Nil(t, spend.Sign(officers[0]))
+	assert.Nil(t, spend.Verify())
+
+	receipt, err = bc.executeTransaction(state, spend, 1, BlockContext{Coinbase: coinbase})
+	assert.Nil(t, err)
+	assert.Equal(t, types.ErrCodeUnauthorized, receipt.ErrorCode)
+	assert.Empty(t, receipt.BalanceChanges)
+
+	assert.Nil(t, spend.Cosign(officers[2]))
+	assert.Nil(t, spend.Verify())
+
+	receipt, err = bc.executeTransaction(state, spend, 2, BlockContext{Coinbase: coinbase})
+	assert.Nil(t, err)
+	assert.Equal(t, types.ReceiptStatusSuccess, receipt.Status)
+
+	funding, err = state.GetAccount(treasury)
+	assert.Nil(t, err)
+	assert.Equal(t, coins(49), funding.Balance)
+}
+
+func TestMultisigOneOfTwo(t *testing.T) {
+	bc := newMemoryChain(t)
+	state := NewState(bc.db)
+	coinbase := types.GeneratePrivateKey().PublicKey.Address()
+	receiver := types.GeneratePrivateKey().PublicKey.Address()
+
+	officer := types.GeneratePrivateKey()
+	policy, err := types.NewMultisigPolicy(1, []common.Address{officer.PublicKey.Address(), types.GeneratePrivateKey().PublicKey.Address()})
+	assert.Nil(t, err)
+	assert.Nil(t, state.SetMultisigPolicy(policy))
+
+	treasury := types.CreateAccount(policy.Address())
+	treasury.Balance = coins(10)
+	assert.Nil(t, state.SetAccount(treasury))
+
+	// a single signer of the policy spends without cosignatures
+	spend := types.CreateTransaction(0, policy.Address(), receiver, coins(5), coins(1), nil)
+	assert.Nil(t, spend.Sign(officer))
+	assert.Nil(t, spend.Verify())
+
+	receipt, err := bc.executeTransaction(state, spend, 0, BlockContext{Coinbase: coinbase})
+	assert.Nil(t, err)
+	assert.Equal(t, types.ReceiptStatusSuccess, receipt.Status)
+
+	treasury, err = state.GetAccount(policy.Address())
+	assert.Nil(t, err)
+	assert.Equal(t, coins(4), treasury.Balance)
+}
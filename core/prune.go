@@ -0,0 +1,138 @@
+package core
+
+import (
+	"fmt"
+	"github.com/barreleye-labs/barreleye/barreldb"
+	"github.com/barreleye-labs/barreleye/config"
+	"strconv"
+)
+
+// pruneBatchSize is the most blocks pruned while linking one block, so that turning
+// pruning on for a long chain does not make a single batch huge.
+const pruneBatchSize = 100
+
+func parsePruneDepth(value string) (int32, error) {
+	if value == "" || value == "0" {
+		return 0, nil
+	}
+
+	depth, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || int32(depth) < config.MinPruneDepth {
+		return 0, fmt.Errorf("prune must be 0 or at least %d blocks", config.MinPruneDepth)
+	}
+	return int32(depth), nil
+}
+
+// prune removes the bodies, transactions, receipts and undo records of the blocks up to
+// the height. Headers and the height index are kept, so the chain can still be checked.
+func (bc *Blockchain) prune(batch *barreldb.BarrelDatabase, height int32) error {
+	mark, err := batch.SelectPruneMark()
+	if err != nil {
+		return err
+	}
+
+	if mark == nil {
+		mark = &barreldb.PruneMark{}
+	}
+
+	if mark.Height > height {
+		return nil
+	}
+
+	for i := 0; mark.Height <= height && i < pruneBatchSize; i++ {
+		block, err := batch.SelectHeightBlock(mark.Height)
+		if err != nil {
+			return err
+		}
+
+		if block == nil {
+			return fmt.Errorf("not found block %d for pruning", mark.Height)
+		}
+
+		for j, tx := range block.Transactions {
+			if err = batch.DeleteHashTx(tx.GetHash()); err != nil {
+				return err
+			}
+			if err = batch.DeleteNumberTx(mark.TxCount + uint32(j)); err != nil {
+				return err
+			}
+			if err = batch.DeleteHashReceipt(tx.GetHash()); err != nil {
+				return err
+			}
+		}
+
+		if err = batch.DeleteHashBlock(block.GetHash()); err != nil {
+			return err
+		}
+		if err = batch.DeleteHeightUndo(mark.Height); err != nil {
+			return err
+		}
+
+		mark.TxCount += uint32(len(block.Transactions))
+		mark.Height++
+	}
+
+	_ = bc.logger.Log("msg", "prune blocks", "height", mark.Height-1)
+	return batch.UpsertPruneMark(mark)
+}
+
+// writeSnapshot copies the state after the block of the height into the batch and
+// deletes the snapshots that are no longer kept.
+func writeSnapshot(batch *barreldb.BarrelDatabase, state *State, height int32) error {
+	txCount := uint32(0)
+	lastTxNumber, err := batch.SelectLastTxNumber()
+	if err != nil {
+		return err
+	}
+	if lastTxNumber != nil {
+		txCount = *lastTxNumber + 1
+	}
+
+	for table := range stateTables {
+		err = state.iterate(table, func(key []byte, value []byte) error {
+			return batch.InsertSnapshotState(height, table, key, value)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = batch.InsertSnapshot(&barreldb.Snapshot{Height: height, TxCount: txCount}); err != nil {
+		return err
+	}
+
+	snapshots, err := batch.SelectSnapshots()
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		if snapshot.Height <= height-config.SnapshotsKept*config.SnapshotInterval {
+			if err = batch.DeleteSnapshot(snapshot.Height); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// repairSnapshot returns the latest snapshot a pruned database can be repaired from: its
+// block must not be pruned and must not be above the consistent height.
+func (bc *Blockchain) repairSnapshot(mark *barreldb.PruneMark, consistentHeight int32) (*barreldb.Snapshot, error) {
+	snapshots, err := bc.db.SelectSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	var result *barreldb.Snapshot
+	for _, snapshot := range snapshots {
+		if snapshot.Height >= mark.Height && snapshot.Height <= consistentHeight {
+			result = snapshot
+		}
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("pruned database has no snapshot between heights %d and %d to repair from", mark.Height, consistentHeight)
+	}
+	return result, nil
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// linkTransferBlocks links a genesis block funding the producer and count blocks, each
+// sending 1 from the producer to receiver.
+func linkTransferBlocks(t *testing.T, bc *Blockchain, key *types.PrivateKey, receiver *types.PrivateKey, count int) {
+	alloc := config.GenesisAlloc
+	t.Cleanup(func() { config.GenesisAlloc = alloc })
+	config.GenesisAlloc = map[string]common.Amount{key.PublicKey.Address().String(): coins(100)}
+
+	genesis, err := bc.CreateGenesisBlock()
+	assert.Nil(t, err)
+	assert.Nil(t, bc.LinkBlockWithoutValidation(genesis))
+
+	prevHeader := genesis.Header
+	for i := 0; i < count; i++ {
+		tx := types.CreateTransaction(uint64(i), key.PublicKey.Address(), receiver.PublicKey.Address(), coins(1), coins(1), nil)
+		assert.Nil(t, tx.Sign(key))
+		b, err := types.NewBlockFromPrevHeader(prevHeader, []*types.Transaction{tx})
+		assert.Nil(t, err)
+		assert.Nil(t, bc.FinalizeBlock(b, key.PublicKey.Address()))
+		assert.Nil(t, b.Sign(*key))
+		assert.Nil(t, bc.LinkBlockWithoutValidation(b))
+		prevHeader = b.Header
+	}
+}
+
+func TestPruneAndRepairFromSnapshot(t *testing.T) {
+	defer func(interval int32) { config.SnapshotInterval = interval }(config.SnapshotInterval)
+	config.SnapshotInterval = 4
+
+	bc := newMemoryChain(t)
+	bc.pruneDepth = 3
+
+	key := types.GeneratePrivateKey()
+	receiver := types.GeneratePrivateKey()
+	linkTransferBlocks(t, bc, key, receiver, 10)
+
+	block, err := bc.ReadBlockByHeight(7)
+	assert.Nil(t, err)
+	assert.Nil(t, block)
+
+	block, err = bc.ReadBlockByHeight(8)
+	assert.Nil(t, err)
+	assert.NotNil(t, block)
+
+	header, err := bc.ReadHeaderByHeight(2)
+	assert.Nil(t, err)
+	hash, err := bc.ReadBlockHashByHeight(2)
+	assert.Nil(t, err)
+	assert.Equal(t, (types.BlockHasher{}).Hash(header), *hash)
+
+	// a page of blocks holds the headers of the pruned blocks
+	blocks, err := bc.ReadBlocks(1, 11)
+	assert.Nil(t, err)
+	assert.Equal(t, 11, len(blocks))
+	assert.Equal(t, 1, len(blocks[10-8].Transactions))
+	assert.Equal(t, int32(2), blocks[10-2].Height)
+	assert.Equal(t, *hash, blocks[10-2].Hash)
+	assert.Equal(t, 0, len(blocks[10-2].Transactions))
+
+	pruned, err := bc.IsPrunedHeight(7)
+	assert.Nil(t, err)
+	assert.True(t, pruned)
+	pruned, err = bc.IsPrunedHeight(8)
+	assert.Nil(t, err)
+	assert.False(t, pruned)
+
+	tx, err := bc.db.SelectNumberTx(6)
+	assert.Nil(t, err)
+	assert.Nil(t, tx)
+	tx, err = bc.db.SelectNumberTx(7)
+	assert.Nil(t, err)
+	assert.NotNil(t, tx)
+
+	snapshots, err := bc.db.SelectSnapshots()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(snapshots))
+	assert.Equal(t, int32(4), snapshots[0].Height)
+	assert.Equal(t, int32(8), snapshots[1].Height)
+
+	report, err := bc.CheckConsistency()
+	assert.Nil(t, err)
+	assert.True(t, report.OK(), report.String())
+
+	// break the state, the pruned database is repaired from the snapshot of block 8
+	account, err := bc.ReadAccountByAddress(receiver.PublicKey.Address())
+	assert.Nil(t, err)
+	balance := account.Balance
+	account.Balance = coins(999)
+	assert.Nil(t, bc.WriteAccountWithAddress(account.Address, account))
+
+	// the state is only recomputed from the accounts by the full check
+	report, err = bc.CheckConsistency()
+	assert.Nil(t, err)
+	assert.True(t, report.OK(), report.String())
+
+	report, err = bc.CheckFullConsistency()
+	assert.Nil(t, err)
+	assert.False(t, report.OK())
+
+	assert.Nil(t, bc.Repair(report))
+
+	report, err = bc.CheckConsistency()
+	assert.Nil(t, err)
+	assert.True(t, report.OK(), report.String())
+	assert.Equal(t, int32(10), report.ConsistentHeight)
+
+	account, err = bc.ReadAccountByAddress(receiver.PublicKey.Address())
+	assert.Nil(t, err)
+	assert.Equal(t, balance, account.Balance)
+}
@@ -0,0 +1,32 @@
+package core
+
+import (
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
+)
+
+// BlockReward returns the reward of the block at height according to the halving
+// schedule. The reward actually minted may be lower once the maximum supply is near.
+func BlockReward(height int32) common.Amount {
+	if height < 0 || config.RewardHalvingInterval <= 0 {
+		return config.InitialBlockReward
+	}
+
+	halvings := height / config.RewardHalvingInterval
+	return config.InitialBlockReward.Rsh(uint(halvings))
+}
+
+// MintedReward returns the reward minted for the block at height when supply coins
+// have been minted before it, so that the total supply never exceeds the maximum.
+func MintedReward(height int32, supply common.Amount) common.Amount {
+	left, underflow := config.MaxSupply.Sub(supply)
+	if underflow {
+		return common.Amount{}
+	}
+
+	reward := BlockReward(height)
+	if reward.Gt(left) {
+		return left
+	}
+	return reward
+}
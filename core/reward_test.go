@@ -0,0 +1,27 @@
+package core
+
+import (
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBlockRewardHalving(t *testing.T) {
+	interval := config.RewardHalvingInterval
+	initial := config.InitialBlockReward
+
+	assert.Equal(t, initial, BlockReward(0))
+	assert.Equal(t, initial, BlockReward(interval-1))
+	assert.Equal(t, initial.Rsh(1), BlockReward(interval))
+	assert.Equal(t, initial.Rsh(2), BlockReward(2*interval))
+	assert.Equal(t, common.Amount{}, BlockReward(256*interval))
+}
+
+func TestMintedRewardCapped(t *testing.T) {
+	almostMax, _ := config.MaxSupply.Sub(common.NewAmount(3))
+
+	assert.Equal(t, config.InitialBlockReward, MintedReward(1, common.Amount{}))
+	assert.Equal(t, common.NewAmount(3), MintedReward(1, almostMax))
+	assert.Equal(t, common.Amount{}, MintedReward(1, config.MaxSupply))
+}
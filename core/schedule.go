@@ -0,0 +1,87 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/common/util"
+	"sort"
+	"time"
+)
+
+// ProducerSchedule decides which validator produces the block of each height.
+// Validators take turns by height. The validator at rank 0 is the expected
+// producer of the slot, and the validator at rank n may only produce the block
+// after the validators at lower ranks have timed out.
+type ProducerSchedule struct {
+	validators []common.Address
+	blockTime  time.Duration
+	timeout    time.Duration
+}
+
+func NewProducerSchedule(validators []common.Address, blockTime time.Duration, timeout time.Duration) *ProducerSchedule {
+	sorted := make([]common.Address, len(validators))
+	copy(sorted, validators)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].ToSlice(), sorted[j].ToSlice()) < 0
+	})
+
+	return &ProducerSchedule{
+		validators: sorted,
+		blockTime:  blockTime,
+		timeout:    timeout,
+	}
+}
+
+func ParseValidators(hexAddresses []string) ([]common.Address, error) {
+	validators := []common.Address{}
+	for _, hexAddress := range hexAddresses {
+		b, err := hex.DecodeString(util.Rm0x(hexAddress))
+		if err != nil {
+			return nil, err
+		}
+
+		address, err := common.NewAddressFromBytes(b)
+		if err != nil {
+			return nil, fmt.Errorf("invalid validator address %s", hexAddress)
+		}
+		validators = append(validators, address)
+	}
+	return validators, nil
+}
+
+func (s *ProducerSchedule) Validators() []common.Address {
+	return s.validators
+}
+
+// Producer returns the validator that holds the given rank for the height.
+func (s *ProducerSchedule) Producer(height int32, rank int) common.Address {
+	n := len(s.validators)
+	return s.validators[(int(height)%n+rank)%n]
+}
+
+// Rank returns the position of the address in the producer order of the height.
+// If the address is not a validator -1 will be returned.
+func (s *ProducerSchedule) Rank(height int32, address common.Address) int {
+	for rank := 0; rank < len(s.validators); rank++ {
+		if s.Producer(height, rank).Equal(address) {
+			return rank
+		}
+	}
+	return -1
+}
+
+// SlotDelay is the minimum time between the previous block and a block produced by the given rank.
+func (s *ProducerSchedule) SlotDelay(rank int) time.Duration {
+	return s.blockTime + time.Duration(rank)*s.timeout
+}
+
+// IsDue reports whether the address may produce the block of the height at the given time.
+func (s *ProducerSchedule) IsDue(prevTimestamp int64, height int32, address common.Address, now int64) bool {
+	rank := s.Rank(height, address)
+	if rank < 0 {
+		return false
+	}
+	return now >= prevTimestamp+s.SlotDelay(rank).Nanoseconds()
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newTestSchedule(n int) (*ProducerSchedule, []common.Address) {
+	validators := []common.Address{}
+	for i := 0; i < n; i++ {
+		validators = append(validators, types.GeneratePrivateKey().PublicKey.Address())
+	}
+	return NewProducerSchedule(validators, 7*time.Second, 3*time.Second), validators
+}
+
+func TestScheduleOneProducerPerSlot(t *testing.T) {
+	s, validators := newTestSchedule(3)
+
+	for height := int32(0); height < 30; height++ {
+		ranks := map[int]bool{}
+		for _, validator := range validators {
+			rank := s.Rank(height, validator)
+			assert.False(t, ranks[rank])
+			ranks[rank] = true
+		}
+		assert.Equal(t, 3, len(ranks))
+		assert.Equal(t, s.Producer(height, 0), s.Producer(height+3, 0))
+		assert.NotEqual(t, s.Producer(height, 0), s.Producer(height+1, 0))
+	}
+
+	assert.Equal(t, -1, s.Rank(1, types.GeneratePrivateKey().PublicKey.Address()))
+}
+
+func TestScheduleIsDue(t *testing.T) {
+	s, _ := newTestSchedule(3)
+	prev := time.Now().UnixNano()
+
+	producer := s.Producer(5, 0)
+	backup := s.Producer(5, 1)
+
+	assert.False(t, s.IsDue(prev, 5, producer, prev+(6*time.Second).Nanoseconds()))
+	assert.True(t, s.IsDue(prev, 5, producer, prev+(7*time.Second).Nanoseconds()))
+	assert.False(t, s.IsDue(prev, 5, backup, prev+(9*time.Second).Nanoseconds()))
+	assert.True(t, s.IsDue(prev, 5, backup, prev+(10*time.Second).Nanoseconds()))
+}
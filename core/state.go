@@ -0,0 +1,524 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"github.com/barreleye-labs/barreleye/barreldb"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"github.com/barreleye-labs/barreleye/core/vm"
+	"sort"
+)
+
+// leafHasher returns the hash of a state value as it is committed to the state root.
+// ok is false if the value must be left out of the state root.
+type leafHasher func(value []byte) (hash common.Hash, ok bool, err error)
+
+// stateTables are the tables that make up the state of the chain. Indexes that are
+// derived from other state tables have no leafHasher and are left out of the state root.
+var stateTables = map[string]leafHasher{
+	barreldb.AddressAccountTableName: accountLeaf,
+	barreldb.ChainSupplyTableName:    rawLeaf,
+
+	barreldb.SymbolTokenTableName:         tokenLeaf,
+	barreldb.AddressTokenBalanceTableName: rawLeaf,
+
+	barreldb.AddressMultisigTableName: multisigLeaf,
+
+	barreldb.HashLockTableName:   lockLeaf,
+	barreldb.HeightLockTableName: nil,
+	barreldb.TimeLockTableName:   nil,
+
+	barreldb.AddressCodeTableName:    rawLeaf,
+	barreldb.AddressStorageTableName: rawLeaf,
+}
+
+// totalSupplyKey is the key of the total supply in the chain-supply table.
+var totalSupplyKey = []byte("total")
+
+func accountLeaf(value []byte) (common.Hash, bool, error) {
+	account := new(types.Account)
+	if err := account.Decode(types.NewGobAccountDecoder(bytes.NewBuffer(value))); err != nil {
+		return common.Hash{}, false, err
+	}
+
+	if account.IsEmpty() {
+		return common.Hash{}, false, nil
+	}
+	return types.AccountHasher{}.Hash(account), true, nil
+}
+
+// rawLeaf hashes values that are stored as plain bytes.
+func rawLeaf(value []byte) (common.Hash, bool, error) {
+	return sha256.Sum256(value), true, nil
+}
+
+func tokenLeaf(value []byte) (common.Hash, bool, error) {
+	token := new(types.Token)
+	if err := token.Decode(types.NewGobTokenDecoder(bytes.NewBuffer(value))); err != nil {
+		return common.Hash{}, false, err
+	}
+	return types.TokenHasher{}.Hash(token), true, nil
+}
+
+func multisigLeaf(value []byte) (common.Hash, bool, error) {
+	policy := new(types.MultisigPolicy)
+	if err := policy.Decode(types.NewGobMultisigPolicyDecoder(bytes.NewBuffer(value))); err != nil {
+		return common.Hash{}, false, err
+	}
+	return types.MultisigPolicyHasher{}.Hash(policy), true, nil
+}
+
+func lockLeaf(value []byte) (common.Hash, bool, error) {
+	lock := new(types.Lock)
+	if err := lock.Decode(types.NewGobLockDecoder(bytes.NewBuffer(value))); err != nil {
+		return common.Hash{}, false, err
+	}
+	return types.LockHasher{}.Hash(lock), true, nil
+}
+
+// State buffers changes to the state tables on top of the database. Blocks are
+// executed against a State so that the resulting state root can be checked before
+// anything is written to the database.
+type State struct {
+	db      *barreldb.BarrelDatabase
+	dirty   map[string]map[string][]byte // table name => key => value, nil if deleted
+	journal []stateChange
+
+	// trie is the update of the state trie for the buffered changes, set by Root.
+	trie *trieUpdate
+}
+
+type stateChange struct {
+	table    string
+	key      string
+	prev     []byte
+	wasDirty bool
+}
+
+func NewState(db *barreldb.BarrelDatabase) *State {
+	dirty := make(map[string]map[string][]byte)
+	for table := range stateTables {
+		dirty[table] = make(map[string][]byte)
+	}
+
+	return &State{
+		db:    db,
+		dirty: dirty,
+	}
+}
+
+func (s *State) get(table string, key []byte) ([]byte, error) {
+	if value, ok := s.dirty[table][string(key)]; ok {
+		return value, nil
+	}
+	return s.getCommitted(table, key)
+}
+
+func (s *State) getCommitted(table string, key []byte) ([]byte, error) {
+	value, err := s.db.GetTable(table).Get(key)
+	if err != nil {
+		if err.Error() != common.LevelDBNotFoundError {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return value, nil
+}
+
+func (s *State) put(table string, key []byte, value []byte) {
+	prev, wasDirty := s.dirty[table][string(key)]
+	s.journal = append(s.journal, stateChange{
+		table:    table,
+		key:      string(key),
+		prev:     prev,
+		wasDirty: wasDirty,
+	})
+	s.dirty[table][string(key)] = value
+	s.trie = nil
+}
+
+// iterate calls fn for every key of the table in order, with the buffered changes
+// applied. Deleted keys are skipped.
+func (s *State) iterate(table string, fn func(key []byte, value []byte) error) error {
+	return s.iterateRange(table, nil, nil, fn)
+}
+
+// iterateRange is iterate for the keys with start <= key < limit. A nil limit
+// iterates to the end of the table.
+func (s *State) iterateRange(table string, start []byte, limit []byte, fn func(key []byte, value []byte) error) error {
+	values := make(map[string][]byte)
+	err := s.db.GetTable(table).IterateRange(start, limit, func(key []byte, value []byte) error {
+		values[string(key)] = append([]byte{}, value...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for key, value := range s.dirty[table] {
+		if key >= string(start) && (limit == nil || key < string(limit)) {
+			values[key] = value
+		}
+	}
+
+	keys := []string{}
+	for key, value := range values {
+		if value != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err = fn([]byte(key), values[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Snapshot returns an identifier of the current state that can be passed to RevertToSnapshot.
+func (s *State) Snapshot() int {
+	return len(s.journal)
+}
+
+// RevertToSnapshot discards every change made after the snapshot was taken.
+func (s *State) RevertToSnapshot(snapshot int) {
+	for i := len(s.journal) - 1; i >= snapshot; i-- {
+		change := s.journal[i]
+		if change.wasDirty {
+			s.dirty[change.table][change.key] = change.prev
+		} else {
+			delete(s.dirty[change.table], change.key)
+		}
+	}
+	s.journal = s.journal[:snapshot]
+	s.trie = nil
+}
+
+func (s *State) GetAccount(address common.Address) (*types.Account, error) {
+	data, err := s.get(barreldb.AddressAccountTableName, address.ToSlice())
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, nil
+	}
+
+	account := new(types.Account)
+	if err = account.Decode(types.NewGobAccountDecoder(bytes.NewBuffer(data))); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// GetOrCreateAccount returns the account of the address, or a new empty account if it does not exist yet.
+func (s *State) GetOrCreateAccount(address common.Address) (*types.Account, error) {
+	account, err := s.GetAccount(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if account == nil {
+		account = types.CreateAccount(address)
+	}
+	return account, nil
+}
+
+func (s *State) SetAccount(account *types.Account) error {
+	buf := &bytes.Buffer{}
+	if err := account.Encode(types.NewGobAccountEncoder(buf)); err != nil {
+		return err
+	}
+
+	s.put(barreldb.AddressAccountTableName, account.Address.ToSlice(), buf.Bytes())
+	return nil
+}
+
+// GetTotalSupply returns the amount of coins minted so far.
+func (s *State) GetTotalSupply() (common.Amount, error) {
+	data, err := s.get(barreldb.ChainSupplyTableName, totalSupplyKey)
+	if err != nil {
+		return common.Amount{}, err
+	}
+	return common.AmountFromBytes(data)
+}
+
+func (s *State) SetTotalSupply(supply common.Amount) {
+	value := supply.Bytes32()
+	s.put(barreldb.ChainSupplyTableName, totalSupplyKey, value[:])
+}
+
+func (s *State) GetToken(symbol string) (*types.Token, error) {
+	data, err := s.get(barreldb.SymbolTokenTableName, []byte(symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, nil
+	}
+
+	token := new(types.Token)
+	if err = token.Decode(types.NewGobTokenDecoder(bytes.NewBuffer(data))); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (s *State) SetToken(token *types.Token) error {
+	buf := &bytes.Buffer{}
+	if err := token.Encode(types.NewGobTokenEncoder(buf)); err != nil {
+		return err
+	}
+
+	s.put(barreldb.SymbolTokenTableName, []byte(token.Symbol), buf.Bytes())
+	return nil
+}
+
+// GetMultisigPolicy returns the policy of the address, or nil if it is not a multisig account.
+func (s *State) GetMultisigPolicy(address common.Address) (*types.MultisigPolicy, error) {
+	data, err := s.get(barreldb.AddressMultisigTableName, address.ToSlice())
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, nil
+	}
+
+	policy := new(types.MultisigPolicy)
+	if err = policy.Decode(types.NewGobMultisigPolicyDecoder(bytes.NewBuffer(data))); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *State) SetMultisigPolicy(policy *types.MultisigPolicy) error {
+	buf := &bytes.Buffer{}
+	if err := policy.Encode(types.NewGobMultisigPolicyEncoder(buf)); err != nil {
+		return err
+	}
+
+	s.put(barreldb.AddressMultisigTableName, policy.Address().ToSlice(), buf.Bytes())
+	return nil
+}
+
+func (s *State) SetLock(hash common.Hash, lock *types.Lock) error {
+	buf := &bytes.Buffer{}
+	if err := lock.Encode(types.NewGobLockEncoder(buf)); err != nil {
+		return err
+	}
+
+	s.put(barreldb.HashLockTableName, hash.ToSlice(), buf.Bytes())
+	return nil
+}
+
+func (s *State) DeleteLock(hash common.Hash) {
+	s.put(barreldb.HashLockTableName, hash.ToSlice(), nil)
+}
+
+func (s *State) GetLock(hash common.Hash) (*types.Lock, error) {
+	data, err := s.get(barreldb.HashLockTableName, hash.ToSlice())
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	lock := new(types.Lock)
+	if err = lock.Decode(types.NewGobLockDecoder(bytes.NewBuffer(data))); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// Locks calls fn for every lock, ordered by the hash of the transaction that created it.
+func (s *State) Locks(fn func(hash common.Hash, lock *types.Lock) error) error {
+	return s.iterate(barreldb.HashLockTableName, func(key []byte, value []byte) error {
+		lock := new(types.Lock)
+		if err := lock.Decode(types.NewGobLockDecoder(bytes.NewBuffer(value))); err != nil {
+			return err
+		}
+
+		hash, err := common.HashFromBytes(key)
+		if err != nil {
+			return err
+		}
+		return fn(hash, lock)
+	})
+}
+
+func (s *State) GetCode(address common.Address) ([]byte, error) {
+	return s.get(barreldb.AddressCodeTableName, address.ToSlice())
+}
+
+func (s *State) SetCode(address common.Address, code []byte) {
+	s.put(barreldb.AddressCodeTableName, address.ToSlice(), code)
+}
+
+func (s *State) GetStorage(address common.Address, key [vm.WordSize]byte) ([vm.WordSize]byte, error) {
+	var value [vm.WordSize]byte
+	data, err := s.get(barreldb.AddressStorageTableName, append(address.ToSlice(), key[:]...))
+	if err != nil {
+		return value, err
+	}
+
+	copy(value[:], data)
+	return value, nil
+}
+
+// SetStorage stores a word of contract storage. Zero words are removed.
+func (s *State) SetStorage(address common.Address, key [vm.WordSize]byte, value [vm.WordSize]byte) {
+	if value == ([vm.WordSize]byte{}) {
+		s.put(barreldb.AddressStorageTableName, append(address.ToSlice(), key[:]...), nil)
+		return
+	}
+	s.put(barreldb.AddressStorageTableName, append(address.ToSlice(), key[:]...), value[:])
+}
+
+func tokenBalanceKey(address common.Address, symbol string) []byte {
+	return append(address.ToSlice(), []byte(symbol)...)
+}
+
+func (s *State) GetTokenBalance(address common.Address, symbol string) (common.Amount, error) {
+	data, err := s.get(barreldb.AddressTokenBalanceTableName, tokenBalanceKey(address, symbol))
+	if err != nil {
+		return common.Amount{}, err
+	}
+	return common.AmountFromBytes(data)
+}
+
+// SetTokenBalance stores the token balance of the address. Zero balances are removed.
+func (s *State) SetTokenBalance(address common.Address, symbol string, balance common.Amount) {
+	if balance.IsZero() {
+		s.put(barreldb.AddressTokenBalanceTableName, tokenBalanceKey(address, symbol), nil)
+		return
+	}
+	value := balance.Bytes32()
+	s.put(barreldb.AddressTokenBalanceTableName, tokenBalanceKey(address, symbol), value[:])
+}
+
+// TokenBalances returns the committed balances of every token held by the address.
+func (s *State) TokenBalances(address common.Address) (map[string]common.Amount, error) {
+	balances := make(map[string]common.Amount)
+	err := s.db.GetTable(barreldb.AddressTokenBalanceTableName).IteratePrefix(address.ToSlice(), func(key []byte, value []byte) error {
+		balance, err := common.AmountFromBytes(value)
+		if err != nil {
+			return err
+		}
+		balances[string(key[len(address.ToSlice()):])] = balance
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// Root calculates the state root over the database and the buffered changes. Only the
+// paths of the changed leaves in the stored state trie are hashed again.
+func (s *State) Root() (common.Hash, error) {
+	if s.trie == nil {
+		trie, err := newTrieUpdate(s)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		s.trie = trie
+	}
+	return s.trie.root, nil
+}
+
+// fullRoot calculates the state root from every value of the state tables without
+// the stored state trie.
+func (s *State) fullRoot() (common.Hash, error) {
+	leaves := make(map[common.Hash]common.Hash)
+
+	for table, hashLeaf := range stateTables {
+		if hashLeaf == nil {
+			continue
+		}
+
+		addLeaf := func(key []byte, value []byte) error {
+			if value == nil {
+				return nil
+			}
+
+			hash, ok, err := hashLeaf(value)
+			if err != nil {
+				return err
+			}
+
+			if ok {
+				leaves[sha256.Sum256(append([]byte(table), key...))] = hash
+			}
+			return nil
+		}
+
+		err := s.db.GetTable(table).Iterate(func(key []byte, value []byte) error {
+			if _, ok := s.dirty[table][string(key)]; ok {
+				return nil
+			}
+			return addLeaf(key, value)
+		})
+		if err != nil {
+			return common.Hash{}, err
+		}
+
+		for key, value := range s.dirty[table] {
+			if err = addLeaf([]byte(key), value); err != nil {
+				return common.Hash{}, err
+			}
+		}
+	}
+
+	return types.SparseMerkleRoot(leaves), nil
+}
+
+// Commit writes the buffered changes and the updated state trie to db and returns the
+// values the changed keys had before, so that the changes can be undone later.
+func (s *State) Commit(db *barreldb.BarrelDatabase) ([]barreldb.UndoEntry, error) {
+	if _, err := s.Root(); err != nil {
+		return nil, err
+	}
+
+	undo := []barreldb.UndoEntry{}
+
+	tables := []string{}
+	for table := range s.dirty {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		keys := []string{}
+		for key := range s.dirty[table] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			prev, err := s.getCommitted(table, []byte(key))
+			if err != nil {
+				return nil, err
+			}
+			undo = append(undo, barreldb.UndoEntry{Table: table, Key: []byte(key), Value: prev})
+
+			value := s.dirty[table][key]
+			if value == nil {
+				err = db.GetTable(table).Delete([]byte(key))
+			} else {
+				err = db.GetTable(table).Put([]byte(key), value)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	trieUndo, err := s.trie.apply(s, db)
+	if err != nil {
+		return nil, err
+	}
+	return append(undo, trieUndo...), nil
+}
@@ -0,0 +1,173 @@
+package core
+
+import (
+	"github.com/barreleye-labs/barreleye/core/types"
+)
+
+// validateTokenTx checks what all token transactions have in common.
+func validateTokenTx(tx *types.Transaction) error {
+	if !tx.Value.IsZero() {
+		return types.NewTxError(types.ErrCodeInvalidPayload, "token transactions can not transfer value")
+	}
+	return nil
+}
+
+// createTokenHandler registers a new token owned by the sender and gives it the initial supply.
+type createTokenHandler struct{}
+
+func (createTokenHandler) Validate(tx *types.Transaction) error {
+	if err := validateTokenTx(tx); err != nil {
+		return err
+	}
+
+	if _, err := types.DecodeCreateTokenPayload(tx.Data); err != nil {
+		return types.NewTxError(types.ErrCodeInvalidPayload, "invalid create token payload: "+err.Error())
+	}
+	return nil
+}
+
+func (createTokenHandler) Execute(bc *Blockchain, state *State, ctx *TxContext, tx *types.Transaction) error {
+	payload, err := types.DecodeCreateTokenPayload(tx.Data)
+	if err != nil {
+		return err
+	}
+
+	token, err := state.GetToken(payload.Symbol)
+	if err != nil {
+		return err
+	}
+
+	if token != nil {
+		return types.NewTxError(types.ErrCodeTokenExists, "token "+payload.Symbol+" already exists")
+	}
+
+	token = &types.Token{
+		Symbol:   payload.Symbol,
+		Name:     payload.Name,
+		Owner:    tx.From,
+		Supply:   payload.Supply,
+		Decimals: payload.Decimals,
+		Mintable: payload.Mintable,
+	}
+	if err = state.SetToken(token); err != nil {
+		return err
+	}
+	state.SetTokenBalance(tx.From, token.Symbol, token.Supply)
+
+	_ = bc.logger.Log("msg", "create token", "symbol", token.Symbol, "owner", token.Owner, "supply", token.Supply)
+	return nil
+}
+
+type transferTokenHandler struct{}
+
+func (transferTokenHandler) Validate(tx *types.Transaction) error {
+	if err := validateTokenTx(tx); err != nil {
+		return err
+	}
+
+	if tx.From.Equal(tx.To) {
+		return types.NewTxError(types.ErrCodeSameAddress, "from and to must be different")
+	}
+
+	if _, err := types.DecodeTokenAmountPayload(tx.Data); err != nil {
+		return types.NewTxError(types.ErrCodeInvalidPayload, "invalid token transfer payload: "+err.Error())
+	}
+	return nil
+}
+
+func (transferTokenHandler) Execute(bc *Blockchain, state *State, ctx *TxContext, tx *types.Transaction) error {
+	payload, err := types.DecodeTokenAmountPayload(tx.Data)
+	if err != nil {
+		return err
+	}
+
+	token, err := state.GetToken(payload.Symbol)
+	if err != nil {
+		return err
+	}
+
+	if token == nil {
+		return types.NewTxError(types.ErrCodeUnknownToken, "unknown token "+payload.Symbol)
+	}
+
+	fromBalance, err := state.GetTokenBalance(tx.From, token.Symbol)
+	if err != nil {
+		return err
+	}
+
+	fromBalance, underflow := fromBalance.Sub(payload.Amount)
+	if underflow {
+		return types.NewTxError(types.ErrCodeInsufficientTokens, "insufficient token balance")
+	}
+
+	toBalance, err := state.GetTokenBalance(tx.To, token.Symbol)
+	if err != nil {
+		return err
+	}
+
+	// the balances add up to the supply of the token, so they can not overflow
+	toBalance, _ = toBalance.Add(payload.Amount)
+	state.SetTokenBalance(tx.From, token.Symbol, fromBalance)
+	state.SetTokenBalance(tx.To, token.Symbol, toBalance)
+
+	_ = bc.logger.Log("msg", "transfer token", "symbol", token.Symbol, "from", tx.From, "to", tx.To, "amount", payload.Amount)
+	return nil
+}
+
+// mintTokenHandler adds new supply of a mintable token to the receiver. Only the owner can mint.
+type mintTokenHandler struct{}
+
+func (mintTokenHandler) Validate(tx *types.Transaction) error {
+	if err := validateTokenTx(tx); err != nil {
+		return err
+	}
+
+	if _, err := types.DecodeTokenAmountPayload(tx.Data); err != nil {
+		return types.NewTxError(types.ErrCodeInvalidPayload, "invalid mint token payload: "+err.Error())
+	}
+	return nil
+}
+
+func (mintTokenHandler) Execute(bc *Blockchain, state *State, ctx *TxContext, tx *types.Transaction) error {
+	payload, err := types.DecodeTokenAmountPayload(tx.Data)
+	if err != nil {
+		return err
+	}
+
+	token, err := state.GetToken(payload.Symbol)
+	if err != nil {
+		return err
+	}
+
+	if token == nil {
+		return types.NewTxError(types.ErrCodeUnknownToken, "unknown token "+payload.Symbol)
+	}
+
+	if !token.Owner.Equal(tx.From) {
+		return types.NewTxError(types.ErrCodeNotTokenOwner, "only the owner can mint token "+token.Symbol)
+	}
+
+	if !token.Mintable {
+		return types.NewTxError(types.ErrCodeTokenNotMintable, "token "+token.Symbol+" is not mintable")
+	}
+
+	supply, overflow := token.Supply.Add(payload.Amount)
+	if overflow {
+		return types.NewTxError(types.ErrCodeInvalidPayload, "token supply overflows")
+	}
+
+	toBalance, err := state.GetTokenBalance(tx.To, token.Symbol)
+	if err != nil {
+		return err
+	}
+
+	token.Supply = supply
+	if err = state.SetToken(token); err != nil {
+		return err
+	}
+	toBalance, _ = toBalance.Add(payload.Amount)
+	state.SetTokenBalance(tx.To, token.Symbol, toBalance)
+
+	_ = bc.logger.Log("msg", "mint token", "symbol", token.Symbol, "to", tx.To, "amount", payload.Amount)
+	return nil
+}
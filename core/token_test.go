@@ -0,0 +1,86 @@
+package core
+
+import (
+	"encoding/json"
+	"github.com/barreleye-labs/barreleye/barreldb"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// newMemoryChain returns a blockchain on an empty in-memory database for executing transactions.
+func newMemoryChain(t *testing.T) *Blockchain {
+	db, err := barreldb.NewMemory()
+	assert.Nil(t, err)
+	assert.Nil(t, setTables(db))
+
+	return &Blockchain{logger: log.NewNopLogger(), db: db}
+}
+
+func newTokenTx(t *testing.T, txType types.TxType, nonce uint64, from *types.PrivateKey, to common.Address, payload any) *types.Transaction {
+	data, err := json.Marshal(payload)
+	assert.Nil(t, err)
+
+	tx := types.CreateTransaction(nonce, from.PublicKey.Address(), to, coins(0), coins(1), data)
+	tx.Type = txType
+	assert.Nil(t, tx.Sign(from))
+	return tx
+}
+
+func TestTokenLifecycle(t *testing.T) {
+	bc := newMemoryChain(t)
+	state := NewState(bc.db)
+
+	ownerKey := types.GeneratePrivateKey()
+	owner := ownerKey.PublicKey.Address()
+	holder := types.GeneratePrivateKey().PublicKey.Address()
+	coinbase := types.GeneratePrivateKey().PublicKey.Address()
+
+	account := types.CreateAccount(owner)
+	account.Balance = coins(10)
+	assert.Nil(t, state.SetAccount(account))
+
+	txs := []*types.Transaction{
+		newTokenTx(t, types.TxTypeCreateToken, 0, ownerKey, owner, types.CreateTokenPayload{Symbol: "PTS", Name: "Points", Supply: common.NewAmount(100), Mintable: true}),
+		newTokenTx(t, types.TxTypeTransferToken, 1, ownerKey, holder, types.TokenAmountPayload{Symbol: "PTS", Amount: common.NewAmount(30)}),
+		newTokenTx(t, types.TxTypeMintToken, 2, ownerKey, holder, types.TokenAmountPayload{Symbol: "PTS", Amount: common.NewAmount(5)}),
+		newTokenTx(t, types.TxTypeTransferToken, 3, ownerKey, holder, types.TokenAmountPayload{Symbol: "PTS", Amount: common.NewAmount(71)}),
+		newTokenTx(t, types.TxTypeCreateToken, 4, ownerKey, owner, types.CreateTokenPayload{Symbol: "PTS", Supply: common.NewAmount(1)}),
+	}
+
+	statuses := []types.ReceiptStatus{}
+	for i, tx := range txs {
+		receipt, err := bc.executeTransaction(state, tx, i, BlockContext{Coinbase: coinbase})
+		assert.Nil(t, err)
+		statuses = append(statuses, receipt.Status)
+	}
+
+	assert.Equal(t, []types.ReceiptStatus{
+		types.ReceiptStatusSuccess,
+		types.ReceiptStatusSuccess,
+		types.ReceiptStatusSuccess,
+		types.ReceiptStatusFailed,
+		types.ReceiptStatusFailed,
+	}, statuses)
+
+	token, err := state.GetToken("PTS")
+	assert.Nil(t, err)
+	assert.Equal(t, common.NewAmount(105), token.Supply)
+	assert.Equal(t, owner, token.Owner)
+
+	ownerBalance, err := state.GetTokenBalance(owner, "PTS")
+	assert.Nil(t, err)
+	assert.Equal(t, common.NewAmount(70), ownerBalance)
+
+	holderBalance, err := state.GetTokenBalance(holder, "PTS")
+	assert.Nil(t, err)
+	assert.Equal(t, common.NewAmount(35), holderBalance)
+
+	// every transaction paid its fee, including the failed ones.
+	account, err = state.GetAccount(owner)
+	assert.Nil(t, err)
+	assert.Equal(t, coins(5), account.Balance)
+	assert.Equal(t, uint64(5), account.Nonce)
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"github.com/barreleye-labs/barreleye/common"
 	"github.com/barreleye-labs/barreleye/core/types"
 	"testing"
 
@@ -13,7 +14,7 @@ func TestTransfer(t *testing.T) {
 	toPrivateKey := types.GeneratePrivateKey()
 	tx := &types.Transaction{
 		To:    toPrivateKey.PublicKey.Address(),
-		Value: 666,
+		Value: common.NewAmount(666),
 	}
 
 	assert.Nil(t, tx.Sign(fromPrivateKey))
@@ -38,10 +39,51 @@ func TestVerifyTx(t *testing.T) {
 	assert.Nil(t, tx.Sign(signerPrivateKey))
 	assert.Nil(t, tx.Verify())
 
-	hackerPrivateKey := types.GeneratePrivateKey()
-	tx.Signer = hackerPrivateKey.PublicKey
+	sender, err := tx.Sender()
+	assert.Nil(t, err)
+	assert.Equal(t, signerPrivateKey.PublicKey.Address(), sender)
 
+	// changing the transaction after signing breaks its hash
+	tx.Data = []byte("bar")
 	assert.NotNil(t, tx.Verify())
+
+	tx.Hash = common.Hash{}
+	sender, err = tx.Sender()
+	assert.Nil(t, err)
+	assert.NotEqual(t, signerPrivateKey.PublicKey.Address(), sender)
+}
+
+func TestAuthorizeSender(t *testing.T) {
+	owner := types.GeneratePrivateKey()
+	hacker := types.GeneratePrivateKey()
+	to := types.GeneratePrivateKey().PublicKey.Address()
+
+	tx := types.CreateTransaction(0, owner.PublicKey.Address(), to, coins(1), coins(1), nil)
+	assert.Nil(t, tx.Sign(owner))
+	assert.Nil(t, AuthorizeSender(nil, tx))
+
+	forged := types.CreateTransaction(0, owner.PublicKey.Address(), to, coins(1), coins(1), nil)
+	assert.Nil(t, forged.Sign(hacker))
+	assert.Nil(t, forged.Verify())
+	assert.Equal(t, types.ErrCodeUnauthorized, txErrorCode(AuthorizeSender(nil, forged)))
+}
+
+func TestSignatureCompactFormat(t *testing.T) {
+	privateKey := types.GeneratePrivateKey()
+	tx := types.CreateTransaction(0, privateKey.PublicKey.Address(), common.Address{}, coins(1), coins(1), nil)
+	assert.Nil(t, tx.Sign(privateKey))
+
+	compact := tx.Signature.String()
+	assert.Equal(t, types.SignatureLength*2, len(compact))
+
+	sig, err := types.ParseSignature("0x" + compact)
+	assert.Nil(t, err)
+	assert.Equal(t, tx.Signature, sig)
+
+	_, err = types.ParseSignature(compact[:128])
+	assert.NotNil(t, err)
+	_, err = types.ParseSignature(compact[:128] + "1b")
+	assert.NotNil(t, err)
 }
 
 func TestTxEncodeDecode(t *testing.T) {
@@ -61,12 +103,11 @@ func randomTxWithSignature(t *testing.T) *types.Transaction {
 	toPublicKey := toPrivateKey.PublicKey
 
 	tx := types.Transaction{
-		Nonce:  171, //ab
-		From:   privateKey.PublicKey.Address(),
-		To:     toPublicKey.Address(),
-		Value:  171,         //ab
-		Data:   []byte{171}, //ab
-		Signer: privateKey.PublicKey,
+		Nonce: 171, //ab
+		From:  privateKey.PublicKey.Address(),
+		To:    toPublicKey.Address(),
+		Value: common.NewAmount(171), //ab
+		Data:  []byte{171},           //ab
 	}
 	assert.Nil(t, tx.Sign(privateKey))
 
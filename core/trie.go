@@ -0,0 +1,355 @@
+package core
+
+import (
+	"crypto/sha256"
+	"github.com/barreleye-labs/barreleye/barreldb"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"sort"
+)
+
+// The state trie stores the sparse Merkle tree of the state root in the state-trie
+// table, so that a block only hashes again the paths of the leaves it changes:
+//
+//	"l" + leaf key            => hash of the state value
+//	"n" + depth + key prefix  => hash of a subtree holding more than one leaf
+//	"r"                       => state root
+//
+// A subtree holding a single leaf is represented by the leaf, so nodes are only stored
+// for subtrees with more than one leaf.
+const (
+	trieLeafTag = byte('l')
+	trieNodeTag = byte('n')
+
+	trieKeyBits = common.HashLength * 8
+)
+
+var trieRootKey = []byte("r")
+
+type trieLeaf struct {
+	key   common.Hash
+	value common.Hash
+}
+
+// trieUpdate calculates the state root after the buffered changes of a State and
+// collects the writes that bring the stored trie up to date.
+type trieUpdate struct {
+	table *barreldb.Table
+
+	// rebuild is set if the database has no trie yet, it is then built from every leaf.
+	rebuild bool
+
+	changes map[common.Hash]*common.Hash // leaf key => value hash, nil if removed
+	writes  map[string][]byte            // trie key => value, nil if deleted
+	root    common.Hash
+}
+
+// newTrieUpdate collects the leaves changed by the buffered changes of the state and
+// calculates the new root.
+func newTrieUpdate(s *State) (*trieUpdate, error) {
+	u := &trieUpdate{
+		table:   s.db.GetTable(barreldb.StateTrieTableName),
+		changes: make(map[common.Hash]*common.Hash),
+		writes:  make(map[string][]byte),
+	}
+
+	storedRoot, err := s.getCommitted(barreldb.StateTrieTableName, trieRootKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if storedRoot == nil {
+		u.rebuild = true
+
+		// whatever is left of an earlier trie is replaced.
+		err = u.table.Iterate(func(key []byte, value []byte) error {
+			u.writes[string(key)] = nil
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for table, hashLeaf := range stateTables {
+			if hashLeaf == nil {
+				continue
+			}
+
+			err = s.iterate(table, func(key []byte, value []byte) error {
+				return u.change(table, key, value, hashLeaf)
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		for table, values := range s.dirty {
+			if stateTables[table] == nil {
+				continue
+			}
+
+			for key, value := range values {
+				if err = u.change(table, []byte(key), value, stateTables[table]); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if len(u.changes) == 0 {
+			if u.root, err = common.HashFromBytes(storedRoot); err != nil {
+				return nil, err
+			}
+			return u, nil
+		}
+	}
+
+	keys := make([]common.Hash, 0, len(u.changes))
+	for key, value := range u.changes {
+		keys = append(keys, key)
+
+		if value == nil {
+			u.writes[string(trieLeafKey(key))] = nil
+		} else {
+			u.writes[string(trieLeafKey(key))] = value.ToSlice()
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Compare(keys[j]) < 0
+	})
+
+	if u.root, err = u.node(0, common.Hash{}, keys); err != nil {
+		return nil, err
+	}
+	u.writes[string(trieRootKey)] = u.root.ToSlice()
+	return u, nil
+}
+
+func (u *trieUpdate) change(table string, key []byte, value []byte, hashLeaf leafHasher) error {
+	leafKey := sha256.Sum256(append([]byte(table), key...))
+	if value == nil {
+		u.changes[leafKey] = nil
+		return nil
+	}
+
+	hash, ok, err := hashLeaf(value)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		u.changes[leafKey] = nil
+		return nil
+	}
+	u.changes[leafKey] = &hash
+	return nil
+}
+
+// node returns the hash of the subtree at depth whose keys start with the first depth
+// bits of prefix. keys are the changed leaf keys of the subtree, in order.
+func (u *trieUpdate) node(depth int, prefix common.Hash, keys []common.Hash) (common.Hash, error) {
+	if len(keys) == 0 {
+		return u.storedSubtree(depth, prefix)
+	}
+
+	leaves, err := u.leaves(depth, prefix, keys)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	stored, err := u.storedNode(depth, prefix)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	if len(leaves) < 2 {
+		// the subtree lost its second leaf, its stored nodes are deleted.
+		if stored != nil {
+			u.writes[trieNodeKey(depth, prefix)] = nil
+			if _, _, err = u.children(depth, prefix, keys); err != nil {
+				return common.Hash{}, err
+			}
+		}
+
+		if len(leaves) == 0 {
+			return common.Hash{}, nil
+		}
+		return types.TrieLeafHash(leaves[0].key, leaves[0].value), nil
+	}
+
+	left, right, err := u.children(depth, prefix, keys)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	hash := types.TrieNodeHash(left, right)
+	u.writes[trieNodeKey(depth, prefix)] = hash.ToSlice()
+	return hash, nil
+}
+
+func (u *trieUpdate) children(depth int, prefix common.Hash, keys []common.Hash) (common.Hash, common.Hash, error) {
+	split := sort.Search(len(keys), func(i int) bool {
+		return trieKeyBit(keys[i], depth) == 1
+	})
+
+	left, err := u.node(depth+1, prefix, keys[:split])
+	if err != nil {
+		return common.Hash{}, common.Hash{}, err
+	}
+
+	rightPrefix := prefix
+	rightPrefix[depth/8] |= 1 << (7 - uint(depth%8))
+
+	right, err := u.node(depth+1, rightPrefix, keys[split:])
+	if err != nil {
+		return common.Hash{}, common.Hash{}, err
+	}
+	return left, right, nil
+}
+
+// storedSubtree returns the hash of an unchanged subtree.
+func (u *trieUpdate) storedSubtree(depth int, prefix common.Hash) (common.Hash, error) {
+	stored, err := u.storedNode(depth, prefix)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	if stored != nil {
+		return *stored, nil
+	}
+
+	leaves, err := u.storedLeaves(depth, prefix, 1)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	if len(leaves) == 0 {
+		return common.Hash{}, nil
+	}
+	return types.TrieLeafHash(leaves[0].key, leaves[0].value), nil
+}
+
+// leaves returns up to two leaves of the subtree after the changes.
+func (u *trieUpdate) leaves(depth int, prefix common.Hash, keys []common.Hash) ([]trieLeaf, error) {
+	leaves := []trieLeaf{}
+	for _, key := range keys {
+		if value := u.changes[key]; value != nil {
+			leaves = append(leaves, trieLeaf{key: key, value: *value})
+			if len(leaves) == 2 {
+				return leaves, nil
+			}
+		}
+	}
+
+	stored, err := u.storedLeaves(depth, prefix, 2-len(leaves))
+	if err != nil {
+		return nil, err
+	}
+	return append(leaves, stored...), nil
+}
+
+// storedLeaves returns up to n stored leaves of the subtree that were not changed.
+func (u *trieUpdate) storedLeaves(depth int, prefix common.Hash, n int) ([]trieLeaf, error) {
+	leaves := []trieLeaf{}
+	if u.rebuild {
+		return leaves, nil
+	}
+
+	last := prefix
+	for i := depth; i < trieKeyBits; i++ {
+		last[i/8] |= 1 << (7 - uint(i%8))
+	}
+
+	// the limit is the key after the last leaf key of the subtree.
+	limit := trieLeafKey(last)
+	for i := len(limit) - 1; i >= 0; i-- {
+		limit[i]++
+		if limit[i] != 0 {
+			break
+		}
+	}
+
+	err := u.table.IterateRange(trieLeafKey(prefix), limit, func(key []byte, value []byte) error {
+		leafKey, err := common.HashFromBytes(key[1:])
+		if err != nil {
+			return err
+		}
+		if _, ok := u.changes[leafKey]; ok {
+			return nil
+		}
+
+		leafValue, err := common.HashFromBytes(value)
+		if err != nil {
+			return err
+		}
+
+		leaves = append(leaves, trieLeaf{key: leafKey, value: leafValue})
+		if len(leaves) == n {
+			return barreldb.ErrStopIteration
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+func (u *trieUpdate) storedNode(depth int, prefix common.Hash) (*common.Hash, error) {
+	if u.rebuild || depth >= trieKeyBits {
+		return nil, nil
+	}
+
+	value, err := u.table.Get([]byte(trieNodeKey(depth, prefix)))
+	if err != nil {
+		if err.Error() != common.LevelDBNotFoundError {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	hash, err := common.HashFromBytes(value)
+	if err != nil {
+		return nil, err
+	}
+	return &hash, nil
+}
+
+// apply writes the trie changes to db and returns the values the changed keys had before.
+func (u *trieUpdate) apply(s *State, db *barreldb.BarrelDatabase) ([]barreldb.UndoEntry, error) {
+	keys := make([]string, 0, len(u.writes))
+	for key := range u.writes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	undo := []barreldb.UndoEntry{}
+	for _, key := range keys {
+		prev, err := s.getCommitted(barreldb.StateTrieTableName, []byte(key))
+		if err != nil {
+			return nil, err
+		}
+		undo = append(undo, barreldb.UndoEntry{Table: barreldb.StateTrieTableName, Key: []byte(key), Value: prev})
+
+		if value := u.writes[key]; value == nil {
+			err = db.GetTable(barreldb.StateTrieTableName).Delete([]byte(key))
+		} else {
+			err = db.GetTable(barreldb.StateTrieTableName).Put([]byte(key), value)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return undo, nil
+}
+
+func trieLeafKey(key common.Hash) []byte {
+	return append([]byte{trieLeafTag}, key.ToSlice()...)
+}
+
+func trieNodeKey(depth int, prefix common.Hash) string {
+	return string(append([]byte{trieNodeTag, byte(depth)}, prefix.ToSlice()...))
+}
+
+func trieKeyBit(key common.Hash, depth int) byte {
+	return (key[depth/8] >> (7 - uint(depth%8))) & 1
+}
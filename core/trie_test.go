@@ -0,0 +1,62 @@
+package core
+
+import (
+	"github.com/barreleye-labs/barreleye/barreldb"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"github.com/barreleye-labs/barreleye/core/vm"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func TestStateTrieMatchesFullRoot(t *testing.T) {
+	bc := newMemoryChain(t)
+	r := rand.New(rand.NewSource(1))
+	contract := types.GeneratePrivateKey().PublicKey.Address()
+
+	checkRoot := func(state *State) common.Hash {
+		root, err := state.Root()
+		assert.Nil(t, err)
+		full, err := state.fullRoot()
+		assert.Nil(t, err)
+		assert.Equal(t, full, root)
+		return root
+	}
+
+	for round := 0; round < 50; round++ {
+		state := NewState(bc.db)
+		before := checkRoot(state)
+
+		// rounds set and delete few slots of a small key space, so leaves come and go
+		for i := 0; i < 1+r.Intn(20); i++ {
+			var key, value [vm.WordSize]byte
+			key[0] = byte(r.Intn(64))
+			if r.Intn(3) > 0 {
+				value[0] = byte(1 + r.Intn(255))
+			}
+			state.SetStorage(contract, key, value)
+		}
+		after := checkRoot(state)
+
+		batch := bc.db.NewBatch()
+		undo, err := state.Commit(batch)
+		assert.Nil(t, err)
+		assert.Nil(t, batch.Commit())
+		assert.Equal(t, after, checkRoot(NewState(bc.db)))
+
+		// every other round is undone like a removed block
+		if round%2 == 1 {
+			batch = bc.db.NewBatch()
+			assert.Nil(t, batch.InsertHeightUndo(1, undo))
+			assert.Nil(t, undoState(batch, 1))
+			assert.Nil(t, batch.Commit())
+			assert.Equal(t, before, checkRoot(NewState(bc.db)))
+		}
+	}
+
+	// a database without a stored trie builds it from the state
+	root := checkRoot(NewState(bc.db))
+	assert.Nil(t, bc.db.GetTable(barreldb.StateTrieTableName).Delete(trieRootKey))
+	assert.Equal(t, root, checkRoot(NewState(bc.db)))
+}
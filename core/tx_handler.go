@@ -0,0 +1,148 @@
+package core
+
+import (
+	"fmt"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
+	"github.com/barreleye-labs/barreleye/core/types"
+)
+
+// BlockContext describes the block a transaction is executed in.
+type BlockContext struct {
+	Height    int32
+	Timestamp int64
+	Coinbase  common.Address
+}
+
+// TxContext is passed to the handler executing a transaction. Handlers report the
+// gas they used and the contract they created through it.
+type TxContext struct {
+	BlockContext
+
+	GasUsed         uint64
+	ContractAddress common.Address
+}
+
+// TxHandler validates and executes the transactions of one type.
+type TxHandler interface {
+	// Validate checks the transaction without looking at the state, so that invalid
+	// transactions can be refused before they reach the tx pool.
+	Validate(tx *types.Transaction) error
+	// Execute applies the transaction to the state. The nonce and the fee are handled
+	// by the caller. A *types.TxError marks the transaction as failed.
+	Execute(bc *Blockchain, state *State, ctx *TxContext, tx *types.Transaction) error
+}
+
+var txHandlers = map[types.TxType]TxHandler{
+	types.TxTypeTransfer:       transferHandler{},
+	types.TxTypeCreateToken:    createTokenHandler{},
+	types.TxTypeTransferToken:  transferTokenHandler{},
+	types.TxTypeMintToken:      mintTokenHandler{},
+	types.TxTypeCreateMultisig: createMultisigHandler{},
+	types.TxTypeDeployContract: deployContractHandler{},
+	types.TxTypeCallContract:   callContractHandler{},
+}
+
+// RegisterTxHandler adds the handler of a new transaction type.
+func RegisterTxHandler(txType types.TxType, handler TxHandler) {
+	if _, ok := txHandlers[txType]; ok {
+		panic(fmt.Sprintf("handler of tx type %d is already registered", txType))
+	}
+	txHandlers[txType] = handler
+}
+
+// ValidateTxSize checks the transaction against the size limits of blocks.
+func ValidateTxSize(tx *types.Transaction) error {
+	if len(tx.Data) > config.MaxTxDataSize {
+		return common.ErrTxDataTooLarge
+	}
+
+	if tx.Size() > config.MaxBlockSize {
+		return common.ErrTxTooLarge
+	}
+	return nil
+}
+
+// ValidateTransaction runs the validation of the handler of the transaction type.
+func ValidateTransaction(tx *types.Transaction) error {
+	if tx.ChainID != config.ChainID {
+		return types.NewTxError(types.ErrCodeWrongChain, fmt.Sprintf("tx is for chain %d, not chain %d", tx.ChainID, config.ChainID))
+	}
+
+	if err := ValidateTxSize(tx); err != nil {
+		return types.NewTxError(types.ErrCodeTooLarge, err.Error())
+	}
+
+	handler, ok := txHandlers[tx.Type]
+	if !ok {
+		return types.NewTxError(types.ErrCodeInvalidPayload, fmt.Sprintf("unknown tx type %d", tx.Type))
+	}
+
+	if tx.UnlockHeight < 0 || tx.UnlockTime < 0 {
+		return types.NewTxError(types.ErrCodeInvalidPayload, "unlock point can not be negative")
+	}
+
+	if tx.ValidUntil < 0 {
+		return types.NewTxError(types.ErrCodeInvalidPayload, "valid until height can not be negative")
+	}
+
+	if tx.IsLocked() && tx.Type != types.TxTypeTransfer {
+		return types.NewTxError(types.ErrCodeInvalidPayload, "only transfers can be time-locked")
+	}
+	return handler.Validate(tx)
+}
+
+type transferHandler struct{}
+
+func (transferHandler) Validate(tx *types.Transaction) error {
+	if tx.From.Equal(tx.To) {
+		return types.NewTxError(types.ErrCodeSameAddress, "from and to must be different")
+	}
+	return nil
+}
+
+func (transferHandler) Execute(bc *Blockchain, state *State, ctx *TxContext, tx *types.Transaction) error {
+	if tx.IsLocked() {
+		if err := lockTransfer(state, tx); err != nil {
+			return err
+		}
+
+		_ = bc.logger.Log(
+			"msg", "locked transfer",
+			"from", tx.From,
+			"to", tx.To,
+			"value", tx.Value,
+			"unlockHeight", tx.UnlockHeight,
+			"unlockTime", tx.UnlockTime)
+		return nil
+	}
+
+	fromAccount, err := state.GetOrCreateAccount(tx.From)
+	if err != nil {
+		return err
+	}
+
+	toAccount, err := state.GetOrCreateAccount(tx.To)
+	if err != nil {
+		return err
+	}
+
+	if err = fromAccount.Transfer(toAccount, tx.Value); err != nil {
+		return err
+	}
+
+	if err = state.SetAccount(fromAccount); err != nil {
+		return err
+	}
+	if err = state.SetAccount(toAccount); err != nil {
+		return err
+	}
+
+	_ = bc.logger.Log(
+		"msg", "transfer",
+		"from", fromAccount.Address,
+		"to", toAccount.Address,
+		"value", tx.Value)
+
+	return nil
+}
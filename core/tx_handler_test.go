@@ -0,0 +1,93 @@
+package core
+
+import (
+	"errors"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// coins returns an amount of whole coins.
+func coins(n uint64) common.Amount {
+	return common.CoinAmount(n, config.Decimals)
+}
+
+func txErrorCode(err error) types.ErrorCode {
+	var txErr *types.TxError
+	if !errors.As(err, &txErr) {
+		return types.ErrCodeNone
+	}
+	return txErr.Code
+}
+
+func TestValidateTransaction(t *testing.T) {
+	from := types.GeneratePrivateKey().PublicKey.Address()
+	to := types.GeneratePrivateKey().PublicKey.Address()
+
+	assert.Nil(t, ValidateTransaction(types.CreateTransaction(0, from, to, coins(1), coins(1), nil)))
+
+	sameAddress := types.CreateTransaction(0, from, from, coins(1), coins(1), nil)
+	assert.Equal(t, types.ErrCodeSameAddress, txErrorCode(ValidateTransaction(sameAddress)))
+
+	unknown := types.CreateTransaction(0, from, to, coins(1), coins(1), nil)
+	unknown.Type = types.TxType(200)
+	assert.Equal(t, types.ErrCodeInvalidPayload, txErrorCode(ValidateTransaction(unknown)))
+
+	badPayload := types.CreateTransaction(0, from, to, coins(0), coins(1), []byte("{"))
+	badPayload.Type = types.TxTypeTransferToken
+	assert.Equal(t, types.ErrCodeInvalidPayload, txErrorCode(ValidateTransaction(badPayload)))
+
+	otherChain := types.CreateTransaction(0, from, to, coins(1), coins(1), nil)
+	otherChain.ChainID++
+	assert.Equal(t, types.ErrCodeWrongChain, txErrorCode(ValidateTransaction(otherChain)))
+
+	tooLarge := types.CreateTransaction(0, from, to, coins(1), coins(1), make([]byte, config.MaxTxDataSize+1))
+	assert.Equal(t, types.ErrCodeTooLarge, txErrorCode(ValidateTransaction(tooLarge)))
+}
+
+func TestWrongChainBlockRejected(t *testing.T) {
+	bc, key := newProducingChain(t)
+
+	from := types.GeneratePrivateKey()
+	tx := types.CreateTransaction(0, from.PublicKey.Address(), key.PublicKey.Address(), coins(1), coins(1), nil)
+	tx.ChainID++
+	assert.Nil(t, tx.Sign(from))
+
+	prevHeader, err := bc.ReadLastHeader()
+	assert.Nil(t, err)
+
+	b, err := types.NewBlockFromPrevHeader(prevHeader, []*types.Transaction{tx})
+	assert.Nil(t, err)
+	assert.Equal(t, common.ErrWrongChain, bc.FinalizeBlock(b, key.PublicKey.Address()))
+}
+
+func TestExpiredTransaction(t *testing.T) {
+	bc := newMemoryChain(t)
+	state := NewState(bc.db)
+
+	key := types.GeneratePrivateKey()
+	from := key.PublicKey.Address()
+	to := types.GeneratePrivateKey().PublicKey.Address()
+
+	account := types.CreateAccount(from)
+	account.Balance = coins(10)
+	assert.Nil(t, state.SetAccount(account))
+
+	tx := types.CreateTransaction(0, from, to, coins(5), coins(1), nil)
+	tx.ValidUntil = 3
+	assert.Nil(t, tx.Sign(key))
+
+	receipt, err := bc.executeTransaction(state, tx, 0, BlockContext{Height: 4, Coinbase: to})
+	assert.Nil(t, err)
+	assert.Equal(t, types.ErrCodeExpired, receipt.ErrorCode)
+
+	account, err = state.GetAccount(from)
+	assert.Nil(t, err)
+	assert.Equal(t, coins(10), account.Balance)
+
+	receipt, err = bc.executeTransaction(state, tx, 0, BlockContext{Height: 3, Coinbase: to})
+	assert.Nil(t, err)
+	assert.Equal(t, types.ReceiptStatusSuccess, receipt.Status)
+}
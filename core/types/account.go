@@ -8,17 +8,23 @@ import (
 type Account struct {
 	Address common.Address
 	Nonce   uint64
-	Balance uint64
+	Balance common.Amount // spendable balance
+	Locked  common.Amount // balance received in time-locked transfers that is not released yet
 }
 
 func CreateAccount(address common.Address) *Account {
 	return &Account{
 		Address: address,
 		Nonce:   uint64(0),
-		Balance: uint64(0),
 	}
 }
 
+// IsEmpty reports whether the account has neither a balance nor sent transactions.
+// Empty accounts are not part of the state root.
+func (a *Account) IsEmpty() bool {
+	return a.Nonce == 0 && a.Balance.IsZero() && a.Locked.IsZero()
+}
+
 func (a *Account) Decode(dec Decoder[*Account]) error {
 	return dec.Decode(a)
 }
@@ -27,26 +33,49 @@ func (a *Account) Encode(enc Encoder[*Account]) error {
 	return enc.Encode(a)
 }
 
-func (a *Account) Transfer(to *Account, amount uint64) error {
-	if a.Balance < amount {
+func (a *Account) Transfer(to *Account, amount common.Amount) error {
+	if a.Balance.Lt(amount) {
 		return fmt.Errorf("insufficient account balance")
 	}
 
 	if err := a.SubBalance(amount); err != nil {
 		return err
 	}
-	to.AddBalance(amount)
-	return nil
+	return to.AddBalance(amount)
 }
 
-func (a *Account) AddBalance(amount uint64) {
-	a.Balance += amount
+func (a *Account) AddBalance(amount common.Amount) error {
+	balance, overflow := a.Balance.Add(amount)
+	if overflow {
+		return fmt.Errorf("balance overflows 256 bits")
+	}
+	a.Balance = balance
+	return nil
 }
 
-func (a *Account) SubBalance(amount uint64) error {
-	if a.Balance < amount {
+func (a *Account) SubBalance(amount common.Amount) error {
+	balance, underflow := a.Balance.Sub(amount)
+	if underflow {
 		return fmt.Errorf("balance cannot be negative")
 	}
-	a.Balance -= amount
+	a.Balance = balance
+	return nil
+}
+
+func (a *Account) AddLocked(amount common.Amount) error {
+	locked, overflow := a.Locked.Add(amount)
+	if overflow {
+		return fmt.Errorf("locked balance overflows 256 bits")
+	}
+	a.Locked = locked
+	return nil
+}
+
+func (a *Account) SubLocked(amount common.Amount) error {
+	locked, underflow := a.Locked.Sub(amount)
+	if underflow {
+		return fmt.Errorf("locked balance cannot be negative")
+	}
+	a.Locked = locked
 	return nil
 }
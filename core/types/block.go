@@ -2,10 +2,10 @@ package types
 
 import (
 	"bytes"
-	"crypto/sha256"
 	"encoding/gob"
 	"fmt"
 	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
 	"github.com/ethereum/go-ethereum/crypto/secp256k1"
 	"time"
 )
@@ -13,6 +13,7 @@ import (
 type Header struct {
 	Version       uint32
 	DataHash      common.Hash
+	StateRoot     common.Hash
 	PrevBlockHash common.Hash
 	Height        int32
 	Timestamp     int64
@@ -38,7 +39,6 @@ type Block struct {
 	*Header
 
 	Transactions []*Transaction
-	Signer       PublicKey
 	Signature    *Signature
 
 	Extra string
@@ -68,7 +68,7 @@ func NewBlockFromPrevHeader(prevHeader *Header, txs []*Transaction) (*Block, err
 	}
 
 	header := &Header{
-		Version:       1,
+		Version:       config.BlockVersion(prevHeader.Height + 1),
 		Height:        prevHeader.Height + 1,
 		DataHash:      dataHash,
 		PrevBlockHash: BlockHasher{}.Hash(prevHeader),
@@ -90,19 +90,37 @@ func (b *Block) Sign(privateKey PrivateKey) error {
 		return err
 	}
 
-	b.Signer = privateKey.PublicKey
 	b.Signature = sig
 
 	return nil
 }
 
+// Producer returns the address recovered from the signature of the block, the zero
+// address for the genesis block, which is not signed, or an invalid signature.
+func (b *Block) Producer() common.Address {
+	if b.Signature == nil {
+		return common.Address{}
+	}
+
+	address, err := b.Signature.RecoverAddress(BlockHasher{}.Hash(b.Header).ToSlice())
+	if err != nil {
+		return common.Address{}
+	}
+	return address
+}
+
 func (b *Block) Verify() error {
 	if b.Signature == nil {
 		return fmt.Errorf("block has no signature")
 	}
 
-	if !b.Signature.Verify(b.Signer, b.GetHash().ToSlice()) {
-		return fmt.Errorf("block has invalid signature")
+	hash := BlockHasher{}.Hash(b.Header)
+	if !b.Hash.IsZero() && b.Hash != hash {
+		return fmt.Errorf("block hash does not match its header")
+	}
+
+	if _, err := b.Signature.RecoverAddress(hash.ToSlice()); err != nil {
+		return fmt.Errorf("block has invalid signature: %w", err)
 	}
 
 	for _, tx := range b.Transactions {
@@ -139,12 +157,13 @@ func (b *Block) GetHash() common.Hash {
 	return b.Hash
 }
 
+// CalculateDataHash returns the Merkle root of the transaction hashes.
 func CalculateDataHash(txx []*Transaction) (hash common.Hash, err error) {
-	buf := []byte{}
+	hashes := []common.Hash{}
 	for _, tx := range txx {
-		buf = append(buf, tx.GetHash().ToSlice()...)
+		hashes = append(hashes, tx.GetHash())
 	}
-	hash = sha256.Sum256(buf)
+	hash = MerkleRoot(hashes)
 
 	return
 }
@@ -0,0 +1,16 @@
+package types
+
+import (
+	"crypto/sha256"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/common/util"
+)
+
+// CreateContractAddress derives the address of the contract deployed by the transaction
+// of from with the given nonce.
+func CreateContractAddress(from common.Address, nonce uint64) common.Address {
+	buf := append([]byte("contract"), from.ToSlice()...)
+	h := sha256.Sum256(append(buf, util.Uint64ToBytes(nonce)...))
+	address, _ := common.NewAddressFromBytes(h[:common.AddressLength])
+	return address
+}
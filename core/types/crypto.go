@@ -27,16 +27,13 @@ func (k *PrivateKey) Encode(enc Encoder[*PrivateKey]) error {
 	return enc.Encode(k)
 }
 
-func (k *PrivateKey) Sign(data []byte) (*Signature, error) {
-	r, s, err := ecdsa.Sign(rand.Reader, k.Key, data)
+// Sign signs a 32-byte hash with a recoverable signature.
+func (k *PrivateKey) Sign(hash []byte) (*Signature, error) {
+	sig, err := crypto.Sign(hash, k.Key)
 	if err != nil {
 		return nil, err
 	}
-
-	return &Signature{
-		R: r,
-		S: s,
-	}, nil
+	return SignatureFromBytes(sig)
 }
 
 func NewPrivateKeyFromReader(r io.Reader) *PrivateKey {
@@ -76,6 +73,28 @@ func CreatePrivateKey(hexKey string) (*PrivateKey, error) {
 	}, nil
 }
 
+// PrivateKeyFromBytes creates a private key from its 32-byte scalar.
+func PrivateKeyFromBytes(b []byte) (*PrivateKey, error) {
+	key, err := crypto.ToECDSA(b)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey := PublicKey{
+		&key.PublicKey,
+	}
+
+	return &PrivateKey{
+		Key:       key,
+		PublicKey: publicKey,
+	}, nil
+}
+
+// Bytes returns the 32-byte scalar of the private key.
+func (k *PrivateKey) Bytes() []byte {
+	return crypto.FromECDSA(k.Key)
+}
+
 type PublicKey struct {
 	Key *ecdsa.PublicKey
 }
@@ -86,62 +105,85 @@ func (k *PublicKey) String1() string {
 
 func (k *PublicKey) Address() common.Address {
 	h := sha256.Sum256(append(k.Key.X.Bytes(), k.Key.Y.Bytes()...))
-	return common.NewAddressFromBytes(h[:20])
+	address, _ := common.NewAddressFromBytes(h[:common.AddressLength])
+	return address
 }
 
-func GetPublicKey(xHex string, yHex string) (*PublicKey, error) {
-	x := new(big.Int)
-	x, ok := x.SetString(util.Rm0x(xHex), 16)
-	if !ok {
-		return nil, fmt.Errorf("invalid signerX")
-	}
+// SignatureLength is the length of a signature in the compact format, R, S and the
+// recovery id V.
+const SignatureLength = 65
 
-	y := new(big.Int)
-	y, ok = y.SetString(util.Rm0x(yHex), 16)
-	if !ok {
-		return nil, fmt.Errorf("invalid signerY")
+// Signature is a recoverable secp256k1 signature. The public key of the signer is
+// recovered from it and the signed hash, so it is not sent along.
+type Signature struct {
+	S *big.Int
+	R *big.Int
+	V uint8
+}
+
+// SignatureFromBytes reads a signature in the compact format, 32 bytes of R, 32 bytes
+// of S and the recovery id V, 0 or 1.
+func SignatureFromBytes(b []byte) (*Signature, error) {
+	if len(b) != SignatureLength {
+		return nil, fmt.Errorf("signature must be %d bytes, got %d", SignatureLength, len(b))
 	}
 
-	ecdsaPublicKey := ecdsa.PublicKey{
-		Curve: secp256k1.S256(),
-		X:     x,
-		Y:     y,
+	if b[64] > 1 {
+		return nil, fmt.Errorf("invalid signature recovery id %d", b[64])
 	}
 
-	return &PublicKey{
-		Key: &ecdsaPublicKey,
+	return &Signature{
+		R: new(big.Int).SetBytes(b[:32]),
+		S: new(big.Int).SetBytes(b[32:64]),
+		V: b[64],
 	}, nil
 }
 
-type Signature struct {
-	S *big.Int
-	R *big.Int
+// ParseSignature reads a signature in the compact format written as hex.
+func ParseSignature(hexSignature string) (*Signature, error) {
+	b, err := hex.DecodeString(util.Rm0x(hexSignature))
+	if err != nil {
+		return nil, err
+	}
+	return SignatureFromBytes(b)
+}
+
+// Bytes returns the signature in the compact format.
+func (sig *Signature) Bytes() []byte {
+	b := make([]byte, SignatureLength)
+	sig.R.FillBytes(b[:32])
+	sig.S.FillBytes(b[32:64])
+	b[64] = sig.V
+	return b
 }
 
 func (sig *Signature) String() string {
-	b := append(sig.S.Bytes(), sig.R.Bytes()...)
-	return hex.EncodeToString(b)
+	return hex.EncodeToString(sig.Bytes())
 }
 
 func (sig *Signature) Verify(publicKey PublicKey, data []byte) bool {
 	return ecdsa.Verify(publicKey.Key, data, sig.R, sig.S)
 }
 
-func GetSignature(rHex string, sHex string) (*Signature, error) {
-	r := new(big.Int)
-	r, ok := r.SetString(util.Rm0x(rHex), 16)
-	if !ok {
-		return nil, fmt.Errorf("invalid signatureR")
+// RecoverPublicKey returns the public key that signed the hash. Signatures with a high S
+// are rejected, so that a signature can not be altered into another valid one.
+func (sig *Signature) RecoverPublicKey(hash []byte) (*PublicKey, error) {
+	if sig.R == nil || sig.S == nil || !crypto.ValidateSignatureValues(sig.V, sig.R, sig.S, true) {
+		return nil, fmt.Errorf("invalid signature values")
 	}
 
-	s := new(big.Int)
-	s, ok = s.SetString(sHex, 16)
-	if !ok {
-		return nil, fmt.Errorf("invalid signatureS")
+	key, err := crypto.SigToPub(hash, sig.Bytes())
+	if err != nil {
+		return nil, err
 	}
+	return &PublicKey{Key: key}, nil
+}
 
-	return &Signature{
-		R: r,
-		S: s,
-	}, nil
+// RecoverAddress returns the address of the key that signed the hash.
+func (sig *Signature) RecoverAddress(hash []byte) (common.Address, error) {
+	publicKey, err := sig.RecoverPublicKey(hash)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return publicKey.Address(), nil
 }
@@ -15,12 +15,16 @@ import (
 func TestKeypairSignVerifySuccess(t *testing.T) {
 	privateKey := GeneratePrivateKey()
 	publicKey := privateKey.PublicKey
-	msg := []byte("hello world")
+	msg := sha256.Sum256([]byte("hello world"))
 
-	sig, err := privateKey.Sign(msg)
+	sig, err := privateKey.Sign(msg[:])
 	assert.Nil(t, err)
 
-	assert.True(t, sig.Verify(publicKey, msg))
+	assert.True(t, sig.Verify(publicKey, msg[:]))
+
+	recovered, err := sig.RecoverPublicKey(msg[:])
+	assert.Nil(t, err)
+	assert.Equal(t, publicKey.Address(), recovered.Address())
 }
 
 func TestKeypairSignVerifySuccess3(t *testing.T) {
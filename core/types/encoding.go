@@ -125,6 +125,90 @@ func (dec *GobAccountDecoder) Decode(a *Account) error {
 	return gob.NewDecoder(dec.r).Decode(a)
 }
 
+type GobTokenEncoder struct {
+	w io.Writer
+}
+
+func NewGobTokenEncoder(w io.Writer) *GobTokenEncoder {
+	return &GobTokenEncoder{
+		w: w,
+	}
+}
+
+func (enc *GobTokenEncoder) Encode(t *Token) error {
+	return gob.NewEncoder(enc.w).Encode(t)
+}
+
+type GobTokenDecoder struct {
+	r io.Reader
+}
+
+func NewGobTokenDecoder(r io.Reader) *GobTokenDecoder {
+	return &GobTokenDecoder{
+		r: r,
+	}
+}
+
+func (dec *GobTokenDecoder) Decode(t *Token) error {
+	return gob.NewDecoder(dec.r).Decode(t)
+}
+
+type GobLockEncoder struct {
+	w io.Writer
+}
+
+func NewGobLockEncoder(w io.Writer) *GobLockEncoder {
+	return &GobLockEncoder{
+		w: w,
+	}
+}
+
+func (enc *GobLockEncoder) Encode(l *Lock) error {
+	return gob.NewEncoder(enc.w).Encode(l)
+}
+
+type GobLockDecoder struct {
+	r io.Reader
+}
+
+func NewGobLockDecoder(r io.Reader) *GobLockDecoder {
+	return &GobLockDecoder{
+		r: r,
+	}
+}
+
+func (dec *GobLockDecoder) Decode(l *Lock) error {
+	return gob.NewDecoder(dec.r).Decode(l)
+}
+
+type GobMultisigPolicyEncoder struct {
+	w io.Writer
+}
+
+func NewGobMultisigPolicyEncoder(w io.Writer) *GobMultisigPolicyEncoder {
+	return &GobMultisigPolicyEncoder{
+		w: w,
+	}
+}
+
+func (enc *GobMultisigPolicyEncoder) Encode(p *MultisigPolicy) error {
+	return gob.NewEncoder(enc.w).Encode(p)
+}
+
+type GobMultisigPolicyDecoder struct {
+	r io.Reader
+}
+
+func NewGobMultisigPolicyDecoder(r io.Reader) *GobMultisigPolicyDecoder {
+	return &GobMultisigPolicyDecoder{
+		r: r,
+	}
+}
+
+func (dec *GobMultisigPolicyDecoder) Decode(p *MultisigPolicy) error {
+	return gob.NewDecoder(dec.r).Decode(p)
+}
+
 type GobPrivateKeyEncoder struct {
 	w io.Writer
 }
@@ -152,3 +236,31 @@ func NewGobPrivateKeyDecoder(r io.Reader) *GobPrivateKeyDecoder {
 func (dec *GobPrivateKeyDecoder) Decode(key *PrivateKey) error {
 	return gob.NewDecoder(dec.r).Decode(key)
 }
+
+type GobReceiptEncoder struct {
+	w io.Writer
+}
+
+func NewGobReceiptEncoder(w io.Writer) *GobReceiptEncoder {
+	return &GobReceiptEncoder{
+		w: w,
+	}
+}
+
+func (enc *GobReceiptEncoder) Encode(r *Receipt) error {
+	return gob.NewEncoder(enc.w).Encode(r)
+}
+
+type GobReceiptDecoder struct {
+	r io.Reader
+}
+
+func NewGobReceiptDecoder(r io.Reader) *GobReceiptDecoder {
+	return &GobReceiptDecoder{
+		r: r,
+	}
+}
+
+func (dec *GobReceiptDecoder) Decode(r *Receipt) error {
+	return gob.NewDecoder(dec.r).Decode(r)
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"github.com/barreleye-labs/barreleye/common"
 	"github.com/barreleye-labs/barreleye/common/util"
+	"github.com/barreleye-labs/barreleye/config"
 	"log"
 )
 
@@ -22,10 +23,16 @@ func (BlockHasher) Hash(header *Header) common.Hash {
 
 	_ = binary.Write(buf, binary.LittleEndian, header.Version)
 	_ = binary.Write(buf, binary.LittleEndian, header.DataHash)
+	_ = binary.Write(buf, binary.LittleEndian, header.StateRoot)
 	_ = binary.Write(buf, binary.LittleEndian, header.PrevBlockHash)
 	_ = binary.Write(buf, binary.LittleEndian, header.Height)
 	_ = binary.Write(buf, binary.LittleEndian, header.Timestamp)
 
+	// from version 2 on the hash commits to the chain, see config.ForkChainHash.
+	if header.Version >= 2 {
+		_ = binary.Write(buf, binary.LittleEndian, config.ChainID)
+	}
+
 	return sha256.Sum256(buf.Bytes())
 }
 
@@ -33,16 +40,28 @@ type TxHasher struct{}
 
 // Hash will hash the whole bytes of the TX no exception.
 func (TxHasher) Hash(tx *Transaction) common.Hash {
+	txType := []byte{byte(tx.Type)}
+	chainID := util.Uint64ToBytes(tx.ChainID)
 	nonce := util.Uint64ToBytes(tx.Nonce)
 	from := tx.From.ToSlice()
 	to := tx.To.ToSlice()
-	value := util.Uint64ToBytes(tx.Value)
+	value := tx.Value.Bytes32()
+	fee := tx.Fee.Bytes32()
+	unlockHeight := util.Int64ToBytes(int64(tx.UnlockHeight))
+	unlockTime := util.Int64ToBytes(tx.UnlockTime)
+	validUntil := util.Int64ToBytes(int64(tx.ValidUntil))
 	data := tx.Data
 	buf := new(bytes.Buffer)
+	_ = binary.Write(buf, binary.LittleEndian, txType)
+	_ = binary.Write(buf, binary.LittleEndian, chainID)
 	_ = binary.Write(buf, binary.LittleEndian, nonce)
 	_ = binary.Write(buf, binary.LittleEndian, from)
 	_ = binary.Write(buf, binary.LittleEndian, to)
 	_ = binary.Write(buf, binary.LittleEndian, value)
+	_ = binary.Write(buf, binary.LittleEndian, fee)
+	_ = binary.Write(buf, binary.LittleEndian, unlockHeight)
+	_ = binary.Write(buf, binary.LittleEndian, unlockTime)
+	_ = binary.Write(buf, binary.LittleEndian, validUntil)
 	_ = binary.Write(buf, binary.LittleEndian, data)
 
 	msgHash := fmt.Sprintf(
@@ -56,5 +75,63 @@ func (TxHasher) Hash(tx *Transaction) common.Hash {
 		panic("internal server error")
 	}
 
-	return common.HashFromBytes(message)
+	hash, _ := common.HashFromBytes(message)
+	return hash
+}
+
+type TokenHasher struct{}
+
+func (TokenHasher) Hash(token *Token) common.Hash {
+	buf := new(bytes.Buffer)
+
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(token.Symbol)))
+	_ = binary.Write(buf, binary.LittleEndian, []byte(token.Symbol))
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(token.Name)))
+	_ = binary.Write(buf, binary.LittleEndian, []byte(token.Name))
+	_ = binary.Write(buf, binary.LittleEndian, token.Owner)
+	_ = binary.Write(buf, binary.LittleEndian, token.Supply.Bytes32())
+	_ = binary.Write(buf, binary.LittleEndian, token.Decimals)
+	_ = binary.Write(buf, binary.LittleEndian, token.Mintable)
+
+	return sha256.Sum256(buf.Bytes())
+}
+
+type MultisigPolicyHasher struct{}
+
+func (MultisigPolicyHasher) Hash(policy *MultisigPolicy) common.Hash {
+	buf := new(bytes.Buffer)
+
+	_ = binary.Write(buf, binary.LittleEndian, []byte("multisig"))
+	_ = binary.Write(buf, binary.LittleEndian, policy.Threshold)
+	for _, signer := range policy.Signers {
+		_ = binary.Write(buf, binary.LittleEndian, signer)
+	}
+
+	return sha256.Sum256(buf.Bytes())
+}
+
+type LockHasher struct{}
+
+func (LockHasher) Hash(lock *Lock) common.Hash {
+	buf := new(bytes.Buffer)
+
+	_ = binary.Write(buf, binary.LittleEndian, lock.Address)
+	_ = binary.Write(buf, binary.LittleEndian, lock.Amount.Bytes32())
+	_ = binary.Write(buf, binary.LittleEndian, lock.UnlockHeight)
+	_ = binary.Write(buf, binary.LittleEndian, lock.UnlockTime)
+
+	return sha256.Sum256(buf.Bytes())
+}
+
+type AccountHasher struct{}
+
+func (AccountHasher) Hash(account *Account) common.Hash {
+	buf := new(bytes.Buffer)
+
+	_ = binary.Write(buf, binary.LittleEndian, account.Address)
+	_ = binary.Write(buf, binary.LittleEndian, account.Nonce)
+	_ = binary.Write(buf, binary.LittleEndian, account.Balance.Bytes32())
+	_ = binary.Write(buf, binary.LittleEndian, account.Locked.Bytes32())
+
+	return sha256.Sum256(buf.Bytes())
 }
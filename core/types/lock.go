@@ -0,0 +1,27 @@
+package types
+
+import (
+	"github.com/barreleye-labs/barreleye/common"
+)
+
+// Lock holds an amount sent to Address until the chain reaches the unlock point.
+// A zero UnlockHeight or UnlockTime is ignored; if both are set, both must be reached.
+type Lock struct {
+	Address      common.Address
+	Amount       common.Amount
+	UnlockHeight int32
+	UnlockTime   int64 // unix nano, compared with the block timestamp
+}
+
+// IsDue reports whether the lock is released by the block with the given height and timestamp.
+func (l *Lock) IsDue(height int32, timestamp int64) bool {
+	return height >= l.UnlockHeight && timestamp >= l.UnlockTime
+}
+
+func (l *Lock) Decode(dec Decoder[*Lock]) error {
+	return dec.Decode(l)
+}
+
+func (l *Lock) Encode(enc Encoder[*Lock]) error {
+	return enc.Encode(l)
+}
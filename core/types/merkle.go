@@ -0,0 +1,130 @@
+package types
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/barreleye-labs/barreleye/common"
+)
+
+const (
+	merkleLeafPrefix = byte(0)
+	merkleNodePrefix = byte(1)
+)
+
+// MerkleProofNode is a sibling hash on the path from a leaf to the root.
+// Left is true if the sibling is the left child of its parent.
+type MerkleProofNode struct {
+	Hash common.Hash
+	Left bool
+}
+
+type MerkleProof struct {
+	Path []MerkleProofNode
+}
+
+func merkleLeaf(hash common.Hash) common.Hash {
+	return sha256.Sum256(append([]byte{merkleLeafPrefix}, hash.ToSlice()...))
+}
+
+func merkleNode(left common.Hash, right common.Hash) common.Hash {
+	buf := append([]byte{merkleNodePrefix}, left.ToSlice()...)
+	return sha256.Sum256(append(buf, right.ToSlice()...))
+}
+
+// nextMerkleLevel pairs the nodes of a level. The last node of a level with an odd
+// number of nodes is moved up unchanged.
+func nextMerkleLevel(level []common.Hash) []common.Hash {
+	next := []common.Hash{}
+	for i := 0; i < len(level); i += 2 {
+		if i+1 == len(level) {
+			next = append(next, level[i])
+			continue
+		}
+		next = append(next, merkleNode(level[i], level[i+1]))
+	}
+	return next
+}
+
+// MerkleRoot calculates the root of a binary Merkle tree over the given hashes.
+// The root of an empty tree is the hash of no data.
+func MerkleRoot(hashes []common.Hash) common.Hash {
+	if len(hashes) == 0 {
+		return sha256.Sum256([]byte{})
+	}
+
+	level := []common.Hash{}
+	for _, hash := range hashes {
+		level = append(level, merkleLeaf(hash))
+	}
+
+	for len(level) > 1 {
+		level = nextMerkleLevel(level)
+	}
+	return level[0]
+}
+
+// NewMerkleProof creates a proof that the hash at index is part of the tree over hashes.
+func NewMerkleProof(hashes []common.Hash, index int) (*MerkleProof, error) {
+	if index < 0 || index >= len(hashes) {
+		return nil, fmt.Errorf("the given index (%d) is out of range (%d)", index, len(hashes))
+	}
+
+	level := []common.Hash{}
+	for _, hash := range hashes {
+		level = append(level, merkleLeaf(hash))
+	}
+
+	proof := &MerkleProof{Path: []MerkleProofNode{}}
+	for len(level) > 1 {
+		if index%2 == 1 {
+			proof.Path = append(proof.Path, MerkleProofNode{Hash: level[index-1], Left: true})
+		} else if index+1 < len(level) {
+			proof.Path = append(proof.Path, MerkleProofNode{Hash: level[index+1], Left: false})
+		}
+
+		level = nextMerkleLevel(level)
+		index /= 2
+	}
+	return proof, nil
+}
+
+// VerifyMerkleProof reports whether the proof connects hash to root.
+func VerifyMerkleProof(root common.Hash, hash common.Hash, proof *MerkleProof) bool {
+	if proof == nil {
+		return false
+	}
+
+	node := merkleLeaf(hash)
+	for _, sibling := range proof.Path {
+		if sibling.Left {
+			node = merkleNode(sibling.Hash, node)
+		} else {
+			node = merkleNode(node, sibling.Hash)
+		}
+	}
+	return node.Equal(root)
+}
+
+// NewTxProof creates a proof that the transaction is included in the block.
+func NewTxProof(b *Block, txHash common.Hash) (*MerkleProof, error) {
+	hashes := []common.Hash{}
+	index := -1
+	for i, tx := range b.Transactions {
+		hash := tx.GetHash()
+		if hash.Equal(txHash) {
+			index = i
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if index == -1 {
+		return nil, fmt.Errorf("transaction %s is not in block %s", txHash, b.GetHash())
+	}
+	return NewMerkleProof(hashes, index)
+}
+
+// VerifyTxProof reports whether the proof shows that the transaction is included in
+// the block of the header.
+func VerifyTxProof(header *Header, txHash common.Hash, proof *MerkleProof) bool {
+	return VerifyMerkleProof(header.DataHash, txHash, proof)
+}
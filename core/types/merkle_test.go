@@ -0,0 +1,56 @@
+package types
+
+import (
+	"crypto/sha256"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMerkleRootEmpty(t *testing.T) {
+	assert.Equal(t, common.Hash(sha256.Sum256([]byte{})), MerkleRoot(nil))
+}
+
+func TestMerkleProof(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		hashes := []common.Hash{}
+		for i := 0; i < n; i++ {
+			hashes = append(hashes, RandomHash())
+		}
+		root := MerkleRoot(hashes)
+
+		for i := 0; i < n; i++ {
+			proof, err := NewMerkleProof(hashes, i)
+			assert.Nil(t, err)
+			assert.True(t, VerifyMerkleProof(root, hashes[i], proof))
+			assert.False(t, VerifyMerkleProof(root, RandomHash(), proof))
+		}
+	}
+
+	_, err := NewMerkleProof([]common.Hash{RandomHash()}, 1)
+	assert.NotNil(t, err)
+}
+
+func TestTxProof(t *testing.T) {
+	privateKey := GeneratePrivateKey()
+	txs := []*Transaction{}
+	for i := 0; i < 5; i++ {
+		txs = append(txs, NewRandomTransaction(privateKey))
+	}
+
+	dataHash, err := CalculateDataHash(txs)
+	assert.Nil(t, err)
+	b := &Block{
+		Header:       &Header{Version: 1, Height: 1, DataHash: dataHash},
+		Transactions: txs,
+	}
+
+	for _, tx := range txs {
+		proof, err := NewTxProof(b, tx.GetHash())
+		assert.Nil(t, err)
+		assert.True(t, VerifyTxProof(b.Header, tx.GetHash(), proof))
+	}
+
+	_, err = NewTxProof(b, RandomHash())
+	assert.NotNil(t, err)
+}
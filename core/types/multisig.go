@@ -0,0 +1,113 @@
+package types
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/common/util"
+	"sort"
+)
+
+const MaxMultisigSigners = 16
+
+// MultisigPolicy is stored for a multisig account. Transactions sent from the account
+// must be signed by at least Threshold of the Signers.
+type MultisigPolicy struct {
+	Threshold uint8
+	Signers   []common.Address
+}
+
+// NewMultisigPolicy checks the policy and sorts its signers, so that the same set of
+// signers always results in the same multisig address.
+func NewMultisigPolicy(threshold uint8, signers []common.Address) (*MultisigPolicy, error) {
+	if len(signers) == 0 || len(signers) > MaxMultisigSigners {
+		return nil, fmt.Errorf("multisig must have 1 to %d signers", MaxMultisigSigners)
+	}
+
+	if threshold == 0 || int(threshold) > len(signers) {
+		return nil, fmt.Errorf("multisig threshold must be between 1 and the number of signers")
+	}
+
+	sorted := append([]common.Address{}, signers...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].ToSlice(), sorted[j].ToSlice()) < 0
+	})
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Equal(sorted[i-1]) {
+			return nil, fmt.Errorf("multisig signers must be different")
+		}
+	}
+
+	return &MultisigPolicy{
+		Threshold: threshold,
+		Signers:   sorted,
+	}, nil
+}
+
+// Address derives the address of the multisig account from its policy.
+func (p *MultisigPolicy) Address() common.Address {
+	h := MultisigPolicyHasher{}.Hash(p)
+	address, _ := common.NewAddressFromBytes(h[:common.AddressLength])
+	return address
+}
+
+// IsAuthorized reports whether enough of the signers of the policy are among addresses.
+func (p *MultisigPolicy) IsAuthorized(addresses []common.Address) bool {
+	count := 0
+	for _, signer := range p.Signers {
+		for _, address := range addresses {
+			if signer.Equal(address) {
+				count++
+				break
+			}
+		}
+	}
+	return count >= int(p.Threshold)
+}
+
+func (p *MultisigPolicy) Decode(dec Decoder[*MultisigPolicy]) error {
+	return dec.Decode(p)
+}
+
+func (p *MultisigPolicy) Encode(enc Encoder[*MultisigPolicy]) error {
+	return enc.Encode(p)
+}
+
+// CreateMultisigPayload is the data of a TxTypeCreateMultisig transaction. Signers are
+// hex addresses.
+type CreateMultisigPayload struct {
+	Threshold uint8    `json:"threshold"`
+	Signers   []string `json:"signers"`
+}
+
+func DecodeCreateMultisigPayload(data []byte) (*MultisigPolicy, error) {
+	payload := new(CreateMultisigPayload)
+	if err := json.Unmarshal(data, payload); err != nil {
+		return nil, err
+	}
+
+	signers := []common.Address{}
+	for _, signer := range payload.Signers {
+		b, err := hex.DecodeString(util.Rm0x(signer))
+		if err != nil {
+			return nil, fmt.Errorf("invalid signer address %s", signer)
+		}
+
+		address, err := common.NewAddressFromBytes(b)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signer address %s", signer)
+		}
+		signers = append(signers, address)
+	}
+
+	return NewMultisigPolicy(payload.Threshold, signers)
+}
+
+// Cosignature is an additional signature of a transaction sent from a multisig account.
+// The cosigner is recovered from it.
+type Cosignature struct {
+	Signature *Signature
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
 	"math/rand"
 	"testing"
 	"time"
@@ -16,7 +17,8 @@ func RandomBytes(size int) []byte {
 }
 
 func RandomHash() common.Hash {
-	return common.HashFromBytes(RandomBytes(32))
+	hash, _ := common.HashFromBytes(RandomBytes(common.HashLength))
+	return hash
 }
 
 // NewRandomTransaction return a new random transaction whithout signature.
@@ -25,11 +27,12 @@ func NewRandomTransaction(privateKey *PrivateKey) *Transaction {
 	r := rand.New(s)
 
 	return &Transaction{
-		Nonce: 171, //ab
-		From:  privateKey.PublicKey.Address(),
-		To:    privateKey.PublicKey.Address(),
-		Value: 171, //ab
-		Data:  RandomBytes(r.Intn(1000)),
+		ChainID: config.ChainID,
+		Nonce:   171, //ab
+		From:    privateKey.PublicKey.Address(),
+		To:      privateKey.PublicKey.Address(),
+		Value:   common.NewAmount(171), //ab
+		Data:    RandomBytes(r.Intn(1000)),
 	}
 }
 
@@ -0,0 +1,106 @@
+package types
+
+import (
+	"fmt"
+	"github.com/barreleye-labs/barreleye/common"
+)
+
+type ReceiptStatus uint8
+
+const (
+	ReceiptStatusFailed  ReceiptStatus = 0
+	ReceiptStatusSuccess ReceiptStatus = 1
+)
+
+func (s ReceiptStatus) String() string {
+	if s == ReceiptStatusSuccess {
+		return "success"
+	}
+	return "failed"
+}
+
+type ErrorCode uint16
+
+const (
+	ErrCodeNone                ErrorCode = 0
+	ErrCodeInvalidNonce        ErrorCode = 1
+	ErrCodeInsufficientBalance ErrorCode = 2
+	ErrCodeSameAddress         ErrorCode = 3
+	ErrCodeFeeTooLow           ErrorCode = 4
+	ErrCodeInvalidPayload      ErrorCode = 5
+	ErrCodeTokenExists         ErrorCode = 6
+	ErrCodeUnknownToken        ErrorCode = 7
+	ErrCodeNotTokenOwner       ErrorCode = 8
+	ErrCodeTokenNotMintable    ErrorCode = 9
+	ErrCodeInsufficientTokens  ErrorCode = 10
+	ErrCodeUnauthorized        ErrorCode = 11
+	ErrCodeMultisigExists      ErrorCode = 12
+	ErrCodeNotContract         ErrorCode = 13
+	ErrCodeOutOfGas            ErrorCode = 14
+	ErrCodeContractFailed      ErrorCode = 15
+	ErrCodeWrongChain          ErrorCode = 16
+	ErrCodeExpired             ErrorCode = 17
+	ErrCodeTooLarge            ErrorCode = 18
+)
+
+// TxError is returned when a transaction can not be executed. Unlike other errors it
+// does not stop the block from being linked, the transaction is recorded as failed.
+type TxError struct {
+	Code    ErrorCode
+	Message string
+}
+
+func NewTxError(code ErrorCode, message string) *TxError {
+	return &TxError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *TxError) Error() string {
+	return e.Message
+}
+
+// BlockTxError is returned when a block can not be linked because of one of its
+// transactions. The block is valid again once the transaction is left out.
+type BlockTxError struct {
+	Hash common.Hash
+	Err  error
+}
+
+func (e *BlockTxError) Error() string {
+	return fmt.Sprintf("transaction %s: %v", e.Hash, e.Err)
+}
+
+func (e *BlockTxError) Unwrap() error {
+	return e.Err
+}
+
+type BalanceChange struct {
+	Address common.Address
+	Before  common.Amount
+	After   common.Amount
+}
+
+// Receipt is the result of executing a transaction in a block.
+type Receipt struct {
+	TxHash         common.Hash
+	BlockHash      common.Hash
+	BlockHeight    int32
+	Index          uint32
+	Status         ReceiptStatus
+	ErrorCode      ErrorCode
+	Error          string
+	BalanceChanges []BalanceChange
+
+	GasUsed         uint64
+	ContractAddress common.Address // set if the transaction deployed a contract
+}
+
+func (r *Receipt) Decode(dec Decoder[*Receipt]) error {
+	return dec.Decode(r)
+}
+
+func (r *Receipt) Encode(enc Encoder[*Receipt]) error {
+	return enc.Encode(r)
+}
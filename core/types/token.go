@@ -0,0 +1,87 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/barreleye-labs/barreleye/common"
+)
+
+const maxTokenSymbolLength = 12
+
+// Token is an asset issued by a user next to the base coin. Tokens are identified
+// by their symbol.
+type Token struct {
+	Symbol   string
+	Name     string
+	Owner    common.Address
+	Supply   common.Amount
+	Decimals uint8 // decimal places the amounts of the token are shown with
+	Mintable bool
+}
+
+func (t *Token) Decode(dec Decoder[*Token]) error {
+	return dec.Decode(t)
+}
+
+func (t *Token) Encode(enc Encoder[*Token]) error {
+	return enc.Encode(t)
+}
+
+// CreateTokenPayload is the data of a TxTypeCreateToken transaction. The whole
+// supply is given to the sender, which becomes the owner of the token.
+type CreateTokenPayload struct {
+	Symbol   string        `json:"symbol"`
+	Name     string        `json:"name"`
+	Supply   common.Amount `json:"supply"`
+	Decimals uint8         `json:"decimals"`
+	Mintable bool          `json:"mintable"`
+}
+
+// TokenAmountPayload is the data of TxTypeTransferToken and TxTypeMintToken
+// transactions. The amount is sent or minted to the receiver of the transaction.
+type TokenAmountPayload struct {
+	Symbol string        `json:"symbol"`
+	Amount common.Amount `json:"amount"`
+}
+
+func DecodeCreateTokenPayload(data []byte) (*CreateTokenPayload, error) {
+	payload := new(CreateTokenPayload)
+	if err := json.Unmarshal(data, payload); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateTokenSymbol(payload.Symbol); err != nil {
+		return nil, err
+	}
+
+	if payload.Decimals > common.MaxDecimals {
+		return nil, fmt.Errorf("token can have at most %d decimals", common.MaxDecimals)
+	}
+	return payload, nil
+}
+
+func DecodeTokenAmountPayload(data []byte) (*TokenAmountPayload, error) {
+	payload := new(TokenAmountPayload)
+	if err := json.Unmarshal(data, payload); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateTokenSymbol(payload.Symbol); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// ValidateTokenSymbol checks that the symbol is made of 1 to 12 upper case letters and digits.
+func ValidateTokenSymbol(symbol string) error {
+	if len(symbol) == 0 || len(symbol) > maxTokenSymbolLength {
+		return fmt.Errorf("token symbol must have 1 to %d characters", maxTokenSymbolLength)
+	}
+
+	for _, c := range symbol {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return fmt.Errorf("token symbol must only contain upper case letters and digits")
+		}
+	}
+	return nil
+}
@@ -1,20 +1,51 @@
 package types
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
+)
+
+// TxType tells how a transaction is executed.
+type TxType uint8
+
+const (
+	TxTypeTransfer TxType = iota
+	TxTypeCreateToken
+	TxTypeTransferToken
+	TxTypeMintToken
+	TxTypeCreateMultisig
+	TxTypeDeployContract
+	TxTypeCallContract
 )
 
 type Transaction struct {
+	Type        TxType
+	ChainID     uint64
 	Nonce       uint64
 	BlockHeight int32
 	Timestamp   int64
 	From        common.Address
 	To          common.Address
-	Value       uint64
+	Value       common.Amount
+	Fee         common.Amount
 	Data        []byte
-	Signer      PublicKey
-	Signature   *Signature
+
+	// UnlockHeight and UnlockTime lock the value of a transfer until the chain reaches them.
+	UnlockHeight int32
+	UnlockTime   int64
+
+	// ValidUntil is the last block height the transaction can be executed at, 0 if it does not expire.
+	ValidUntil int32
+
+	// Signature is the recoverable signature of the sender, or of one of the signers of
+	// the multisig account the transaction is sent from.
+	Signature *Signature
+
+	// Cosignatures are the signatures of the other signers of a multisig account.
+	// They are not part of the hash.
+	Cosignatures []Cosignature
 
 	Hash common.Hash
 }
@@ -23,14 +54,17 @@ func CreateTransaction(
 	nonce uint64,
 	from common.Address,
 	to common.Address,
-	value uint64,
+	value common.Amount,
+	fee common.Amount,
 	data []byte) *Transaction {
 	return &Transaction{
-		Nonce: nonce,
-		From:  from,
-		To:    to,
-		Value: value,
-		Data:  data,
+		ChainID: config.ChainID,
+		Nonce:   nonce,
+		From:    from,
+		To:      to,
+		Value:   value,
+		Fee:     fee,
+		Data:    data,
 	}
 }
 
@@ -38,17 +72,18 @@ func CreateSignedTransaction(
 	nonce uint64,
 	from common.Address,
 	to common.Address,
-	value uint64,
+	value common.Amount,
+	fee common.Amount,
 	data []byte,
-	signer PublicKey,
 	signature *Signature) *Transaction {
 	return &Transaction{
+		ChainID:   config.ChainID,
 		Nonce:     nonce,
 		From:      from,
 		To:        to,
 		Value:     value,
+		Fee:       fee,
 		Data:      data,
-		Signer:    signer,
 		Signature: signature,
 	}
 }
@@ -67,25 +102,108 @@ func (tx *Transaction) Sign(privateKey *PrivateKey) error {
 		return err
 	}
 
-	tx.Signer = privateKey.PublicKey
 	tx.Signature = sig
 
 	return nil
 }
 
+// IsLocked reports whether the value of the transaction is time-locked.
+func (tx *Transaction) IsLocked() bool {
+	return tx.UnlockHeight != 0 || tx.UnlockTime != 0
+}
+
+// IsExpired reports whether the transaction can no longer be executed in the block of the height.
+func (tx *Transaction) IsExpired(height int32) bool {
+	return tx.ValidUntil != 0 && height > tx.ValidUntil
+}
+
+// Size returns the length of the encoded transaction, which counts towards the size limit of blocks.
+func (tx *Transaction) Size() int {
+	buf := new(bytes.Buffer)
+	if err := tx.Encode(NewGobTxEncoder(buf)); err != nil {
+		return 0
+	}
+	return buf.Len()
+}
+
+// Cost returns the value plus the fee of the transaction and whether the sum overflowed.
+func (tx *Transaction) Cost() (common.Amount, bool) {
+	return tx.Value.Add(tx.Fee)
+}
+
+// Cosign adds a signature of another signer of the multisig account the transaction is sent from.
+func (tx *Transaction) Cosign(privateKey *PrivateKey) error {
+	sig, err := privateKey.Sign(tx.GetHash().ToSlice())
+	if err != nil {
+		return err
+	}
+
+	tx.Cosignatures = append(tx.Cosignatures, Cosignature{Signature: sig})
+	return nil
+}
+
+// Verify checks that the signature and the cosignatures of the transaction recover to
+// a signer. Whether the signers may send from tx.From depends on the state, see
+// core.AuthorizeSender.
 func (tx *Transaction) Verify() error {
 	if tx.Signature == nil {
 		return fmt.Errorf("transaction has no signature")
 	}
 
-	hash := tx.GetHash()
-	if !tx.Signature.Verify(tx.Signer, hash.ToSlice()) {
-		return fmt.Errorf("invalid transaction signature")
+	if !tx.Hash.IsZero() && tx.Hash != (TxHasher{}).Hash(tx) {
+		return fmt.Errorf("transaction hash does not match its content")
+	}
+
+	if len(tx.Cosignatures) >= MaxMultisigSigners {
+		return fmt.Errorf("transaction has too many cosignatures")
+	}
+
+	for _, cosignature := range tx.Cosignatures {
+		if cosignature.Signature == nil {
+			return fmt.Errorf("transaction has an empty cosignature")
+		}
 	}
 
+	if _, err := tx.SignerAddresses(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// Sender returns the address recovered from the signature of the transaction. The hash
+// is computed again, a cached hash received from a peer is not trusted.
+func (tx *Transaction) Sender() (common.Address, error) {
+	if tx.Signature == nil {
+		return common.Address{}, fmt.Errorf("transaction has no signature")
+	}
+
+	address, err := tx.Signature.RecoverAddress(TxHasher{}.Hash(tx).ToSlice())
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid transaction signature: %w", err)
+	}
+	return address, nil
+}
+
+// SignerAddresses returns the addresses recovered from the signature and the
+// cosignatures of the transaction, the sender first.
+func (tx *Transaction) SignerAddresses() ([]common.Address, error) {
+	sender, err := tx.Sender()
+	if err != nil {
+		return nil, err
+	}
+
+	hash := TxHasher{}.Hash(tx)
+	addresses := []common.Address{sender}
+	for _, cosignature := range tx.Cosignatures {
+		address, err := cosignature.Signature.RecoverAddress(hash.ToSlice())
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction cosignature: %w", err)
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
+
 func (tx *Transaction) Decode(dec Decoder[*Transaction]) error {
 	return dec.Decode(tx)
 }
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"crypto/sha256"
+	"github.com/barreleye-labs/barreleye/common"
+	"sort"
+)
+
+const (
+	trieLeafPrefix = byte(0)
+	trieNodePrefix = byte(1)
+)
+
+// SparseMerkleRoot calculates the root of a sparse Merkle tree with 256 bit keys.
+// An empty subtree hashes to the zero hash and a subtree holding a single leaf is
+// represented by the leaf itself, so the root only depends on the set of leaves.
+func SparseMerkleRoot(leaves map[common.Hash]common.Hash) common.Hash {
+	keys := make([]common.Hash, 0, len(leaves))
+	for key := range leaves {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Compare(keys[j]) < 0
+	})
+
+	return sparseMerkleNode(keys, leaves, 0)
+}
+
+func sparseMerkleNode(keys []common.Hash, leaves map[common.Hash]common.Hash, depth int) common.Hash {
+	if len(keys) == 0 {
+		return common.Hash{}
+	}
+
+	if len(keys) == 1 {
+		return TrieLeafHash(keys[0], leaves[keys[0]])
+	}
+
+	// keys are sorted, so the keys whose bit at depth is set come last.
+	split := sort.Search(len(keys), func(i int) bool {
+		return keyBit(keys[i], depth) == 1
+	})
+
+	left := sparseMerkleNode(keys[:split], leaves, depth+1)
+	right := sparseMerkleNode(keys[split:], leaves, depth+1)
+	return TrieNodeHash(left, right)
+}
+
+// TrieLeafHash hashes a subtree of a sparse Merkle tree that holds a single leaf.
+func TrieLeafHash(key common.Hash, value common.Hash) common.Hash {
+	buf := append([]byte{trieLeafPrefix}, key.ToSlice()...)
+	return sha256.Sum256(append(buf, value.ToSlice()...))
+}
+
+// TrieNodeHash hashes a subtree of a sparse Merkle tree that holds more than one leaf.
+func TrieNodeHash(left common.Hash, right common.Hash) common.Hash {
+	buf := append([]byte{trieNodePrefix}, left.ToSlice()...)
+	return sha256.Sum256(append(buf, right.ToSlice()...))
+}
+
+func keyBit(key common.Hash, depth int) byte {
+	return (key[depth/8] >> (7 - uint(depth%8))) & 1
+}
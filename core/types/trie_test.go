@@ -0,0 +1,35 @@
+package types
+
+import (
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSparseMerkleRootEmpty(t *testing.T) {
+	assert.True(t, SparseMerkleRoot(map[common.Hash]common.Hash{}).IsZero())
+}
+
+func TestSparseMerkleRootDependsOnLeaves(t *testing.T) {
+	leaves := map[common.Hash]common.Hash{}
+	for i := 0; i < 100; i++ {
+		leaves[RandomHash()] = RandomHash()
+	}
+
+	root := SparseMerkleRoot(leaves)
+
+	copied := map[common.Hash]common.Hash{}
+	for key, value := range leaves {
+		copied[key] = value
+	}
+	assert.Equal(t, root, SparseMerkleRoot(copied))
+
+	for key := range copied {
+		copied[key] = RandomHash()
+		break
+	}
+	assert.NotEqual(t, root, SparseMerkleRoot(copied))
+
+	copied[RandomHash()] = RandomHash()
+	assert.NotEqual(t, root, SparseMerkleRoot(copied))
+}
@@ -3,7 +3,9 @@ package core
 import (
 	"fmt"
 	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
 	"github.com/barreleye-labs/barreleye/core/types"
+	"time"
 )
 
 type Validator interface {
@@ -21,8 +23,9 @@ func NewBlockValidator(bc *Blockchain) *BlockValidator {
 }
 
 func (v *BlockValidator) ValidateBlock(b *types.Block) error {
+	// every node builds the genesis block itself, it is never received.
 	if b.Height == 0 {
-		return nil
+		return common.ErrBlockKnown
 	}
 
 	lastBlock, err := v.bc.ReadLastBlock()
@@ -53,14 +56,33 @@ func (v *BlockValidator) ValidateBlock(b *types.Block) error {
 		return common.ErrPrevBlockMismatch
 	}
 
+	if b.Version != config.BlockVersion(b.Height) {
+		return common.ErrBlockVersion
+	}
+
 	if err = b.Verify(); err != nil {
 		return err
 	}
 
-	// 블록 높이가 같은 다른 블록을 수신한 경우 해시값이 작은 블록을 선택함.
+	if err = validateBlockLimits(b); err != nil {
+		return err
+	}
+
+	rank, err := v.validateProducer(b, prevHeader)
+	if err != nil {
+		return err
+	}
+
+	// 블록 높이가 같은 다른 블록을 수신한 경우 스케줄상 우선순위가 높은 블록을 선택하고,
+	// 우선순위가 같으면 해시값이 작은 블록을 선택함.
 	if lastBlock.Height == b.Height {
-		if !lastBlock.Hash.Equal(b.Hash) && lastBlock.Hash.Compare(b.Hash) == 1 {
-			_ = v.bc.logger.Log("msg", "block replacement")
+		if lastBlock.Hash.Equal(b.Hash) {
+			return common.ErrBlockKnown
+		}
+
+		lastRank := v.bc.schedule.Rank(lastBlock.Height, lastBlock.Producer())
+		if rank < lastRank || (rank == lastRank && lastBlock.Hash.Compare(b.Hash) == 1) {
+			_ = v.bc.logger.Log("msg", "block replacement", "rank", rank, "replacedRank", lastRank)
 			if err = v.bc.RemoveLastBlock(); err != nil {
 				return err
 			}
@@ -70,3 +92,52 @@ func (v *BlockValidator) ValidateBlock(b *types.Block) error {
 	}
 	return nil
 }
+
+// validateBlockLimits checks the transactions of the block against the chain and the
+// limits on their number, size and gas.
+func validateBlockLimits(b *types.Block) error {
+	if len(b.Transactions) > config.MaxBlockTxs {
+		return common.ErrBlockTooManyTxs
+	}
+
+	size := 0
+	gas := uint64(0)
+	for _, tx := range b.Transactions {
+		if tx.ChainID != config.ChainID {
+			return common.ErrWrongChain
+		}
+
+		if len(tx.Data) > config.MaxTxDataSize {
+			return common.ErrTxDataTooLarge
+		}
+
+		size += tx.Size()
+		if size > config.MaxBlockSize {
+			return common.ErrBlockTooLarge
+		}
+
+		gas += TxGas(tx)
+		if gas > config.MaxBlockGas {
+			return common.ErrBlockGasLimit
+		}
+	}
+	return nil
+}
+
+// validateProducer checks that the block was signed by a validator that was allowed
+// to produce it at its timestamp and returns the rank of the signer.
+func (v *BlockValidator) validateProducer(b *types.Block, prevHeader *types.Header) (int, error) {
+	rank := v.bc.schedule.Rank(b.Height, b.Producer())
+	if rank < 0 {
+		return rank, common.ErrUnknownProducer
+	}
+
+	if b.Timestamp < prevHeader.Timestamp+v.bc.schedule.SlotDelay(rank).Nanoseconds() {
+		return rank, common.ErrBlockTooEarly
+	}
+
+	if b.Timestamp > time.Now().UnixNano()+config.ProducerTimeout.Nanoseconds() {
+		return rank, common.ErrBlockFromFuture
+	}
+	return rank, nil
+}
@@ -0,0 +1,396 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"github.com/barreleye-labs/barreleye/common"
+	"math/big"
+)
+
+const (
+	WordSize      = 32
+	maxStackDepth = 1024
+)
+
+var (
+	ErrOutOfGas        = errors.New("out of gas")
+	ErrStackUnderflow  = errors.New("stack underflow")
+	ErrStackOverflow   = errors.New("stack overflow")
+	ErrInvalidJump     = errors.New("invalid jump destination")
+	ErrReverted        = errors.New("execution reverted")
+	ErrWriteProtection = errors.New("state can not be changed in a read-only call")
+
+	// ErrInsufficientBalance is returned by StateDB.Transfer if the contract can not
+	// afford the transfer. Like the errors above it only fails the contract call.
+	ErrInsufficientBalance = errors.New("insufficient balance for transfer")
+	// ErrState wraps the other errors of the StateDB, which can not be blamed on the contract.
+	ErrState = errors.New("state error")
+
+	wordModulus = new(big.Int).Lsh(big.NewInt(1), WordSize*8)
+)
+
+// StateDB is the state the contracts run against. Storage words are 32 bytes long and
+// a missing key reads as zero.
+type StateDB interface {
+	GetStorage(contract common.Address, key [WordSize]byte) ([WordSize]byte, error)
+	SetStorage(contract common.Address, key [WordSize]byte, value [WordSize]byte) error
+	GetBalance(address common.Address) (common.Amount, error)
+	Transfer(from common.Address, to common.Address, amount common.Amount) error
+}
+
+// Context describes the call being executed.
+type Context struct {
+	Caller    common.Address
+	Address   common.Address // address of the contract
+	Value     common.Amount
+	Input     []byte
+	Height    int32
+	Timestamp int64
+	ReadOnly  bool
+}
+
+// Result is the outcome of running a contract. Output is set by RETURN.
+type Result struct {
+	Output  []byte
+	GasUsed uint64
+}
+
+type interpreter struct {
+	code  []byte
+	ctx   Context
+	db    StateDB
+	stack []*big.Int
+	gas   uint64
+	used  uint64
+}
+
+// Run executes code with the given amount of gas. Changes made to db before an error
+// is returned must be discarded by the caller.
+func Run(code []byte, ctx Context, db StateDB, gas uint64) (*Result, error) {
+	in := &interpreter{
+		code: code,
+		ctx:  ctx,
+		db:   db,
+		gas:  gas,
+	}
+
+	output, err := in.run()
+	return &Result{Output: output, GasUsed: in.used}, err
+}
+
+func (in *interpreter) useGas(amount uint64) error {
+	if in.gas-in.used < amount {
+		in.used = in.gas
+		return ErrOutOfGas
+	}
+	in.used += amount
+	return nil
+}
+
+func (in *interpreter) push(v *big.Int) error {
+	if len(in.stack) >= maxStackDepth {
+		return ErrStackOverflow
+	}
+	in.stack = append(in.stack, v.Mod(v, wordModulus))
+	return nil
+}
+
+func (in *interpreter) pop() (*big.Int, error) {
+	if len(in.stack) == 0 {
+		return nil, ErrStackUnderflow
+	}
+	v := in.stack[len(in.stack)-1]
+	in.stack = in.stack[:len(in.stack)-1]
+	return v, nil
+}
+
+func (in *interpreter) pop2() (*big.Int, *big.Int, error) {
+	a, err := in.pop()
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := in.pop()
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, b, nil
+}
+
+// operand reads the one byte operand of the instruction at pc.
+func (in *interpreter) operand(pc int) (int, error) {
+	if pc+1 >= len(in.code) {
+		return 0, fmt.Errorf("missing operand at %d", pc)
+	}
+	return int(in.code[pc+1]), nil
+}
+
+func stateError(err error) error {
+	if errors.Is(err, ErrInsufficientBalance) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrState, err)
+}
+
+func toWord(v *big.Int) [WordSize]byte {
+	var word [WordSize]byte
+	v.FillBytes(word[:])
+	return word
+}
+
+// toAmount converts a word to an amount. Words are kept below 2^256, so they always fit.
+func toAmount(v *big.Int) common.Amount {
+	amount, _ := common.AmountFromBig(v)
+	return amount
+}
+
+// toAddress converts a word to an address by its low AddressLength bytes.
+func toAddress(v *big.Int) common.Address {
+	word := toWord(v)
+	address, _ := common.NewAddressFromBytes(word[WordSize-common.AddressLength:])
+	return address
+}
+
+func boolWord(b bool) *big.Int {
+	if b {
+		return big.NewInt(1)
+	}
+	return new(big.Int)
+}
+
+func (in *interpreter) run() ([]byte, error) {
+	for pc := 0; pc < len(in.code); {
+		op := OpCode(in.code[pc])
+		cost, ok := gasCosts[op]
+		if !ok {
+			return nil, fmt.Errorf("invalid opcode 0x%02x at %d", byte(op), pc)
+		}
+		if err := in.useGas(cost); err != nil {
+			return nil, err
+		}
+
+		next := pc + 1
+		switch op {
+		case STOP:
+			return nil, nil
+
+		case ADD, SUB, MUL, DIV, MOD, LT, GT, EQ, AND, OR:
+			a, b, err := in.pop2()
+			if err != nil {
+				return nil, err
+			}
+			if err = in.push(binaryOp(op, a, b)); err != nil {
+				return nil, err
+			}
+
+		case ISZERO, NOT:
+			a, err := in.pop()
+			if err != nil {
+				return nil, err
+			}
+			if op == ISZERO {
+				err = in.push(boolWord(a.Sign() == 0))
+			} else {
+				err = in.push(new(big.Int).Sub(new(big.Int).Sub(wordModulus, big.NewInt(1)), a))
+			}
+			if err != nil {
+				return nil, err
+			}
+
+		case PUSH:
+			n, err := in.operand(pc)
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 || n > WordSize || pc+2+n > len(in.code) {
+				return nil, fmt.Errorf("invalid push at %d", pc)
+			}
+			if err = in.push(new(big.Int).SetBytes(in.code[pc+2 : pc+2+n])); err != nil {
+				return nil, err
+			}
+			next = pc + 2 + n
+
+		case POP:
+			if _, err := in.pop(); err != nil {
+				return nil, err
+			}
+
+		case DUP, SWAP:
+			n, err := in.operand(pc)
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 || n > 16 {
+				return nil, fmt.Errorf("invalid depth %d at %d", n, pc)
+			}
+			if op == DUP {
+				if len(in.stack) < n {
+					return nil, ErrStackUnderflow
+				}
+				err = in.push(new(big.Int).Set(in.stack[len(in.stack)-n]))
+			} else {
+				if len(in.stack) < n+1 {
+					return nil, ErrStackUnderflow
+				}
+				top := len(in.stack) - 1
+				in.stack[top], in.stack[top-n] = in.stack[top-n], in.stack[top]
+			}
+			if err != nil {
+				return nil, err
+			}
+			next = pc + 2
+
+		case CALLER, ADDRESS, CALLVALUE, HEIGHT, TIMESTAMP, CALLDATASIZE:
+			if err := in.push(in.contextWord(op)); err != nil {
+				return nil, err
+			}
+
+		case BALANCE:
+			a, err := in.pop()
+			if err != nil {
+				return nil, err
+			}
+			balance, err := in.db.GetBalance(toAddress(a))
+			if err != nil {
+				return nil, stateError(err)
+			}
+			if err = in.push(balance.ToBig()); err != nil {
+				return nil, err
+			}
+
+		case CALLDATALOAD:
+			a, err := in.pop()
+			if err != nil {
+				return nil, err
+			}
+			var word [WordSize]byte
+			if a.IsInt64() && a.Int64() < int64(len(in.ctx.Input)) {
+				copy(word[:], in.ctx.Input[a.Int64():])
+			}
+			if err = in.push(new(big.Int).SetBytes(word[:])); err != nil {
+				return nil, err
+			}
+
+		case SLOAD:
+			key, err := in.pop()
+			if err != nil {
+				return nil, err
+			}
+			value, err := in.db.GetStorage(in.ctx.Address, toWord(key))
+			if err != nil {
+				return nil, stateError(err)
+			}
+			if err = in.push(new(big.Int).SetBytes(value[:])); err != nil {
+				return nil, err
+			}
+
+		case SSTORE:
+			if in.ctx.ReadOnly {
+				return nil, ErrWriteProtection
+			}
+			key, value, err := in.pop2()
+			if err != nil {
+				return nil, err
+			}
+			if err = in.db.SetStorage(in.ctx.Address, toWord(key), toWord(value)); err != nil {
+				return nil, stateError(err)
+			}
+
+		case JUMP, JUMPI:
+			dest, err := in.pop()
+			if err != nil {
+				return nil, err
+			}
+			jump := true
+			if op == JUMPI {
+				cond, err := in.pop()
+				if err != nil {
+					return nil, err
+				}
+				jump = cond.Sign() != 0
+			}
+			if jump {
+				if !dest.IsInt64() || dest.Int64() >= int64(len(in.code)) {
+					return nil, ErrInvalidJump
+				}
+				next = int(dest.Int64())
+			}
+
+		case TRANSFER:
+			if in.ctx.ReadOnly {
+				return nil, ErrWriteProtection
+			}
+			to, amount, err := in.pop2()
+			if err != nil {
+				return nil, err
+			}
+			if err = in.db.Transfer(in.ctx.Address, toAddress(to), toAmount(amount)); err != nil {
+				return nil, stateError(err)
+			}
+
+		case RETURN:
+			a, err := in.pop()
+			if err != nil {
+				return nil, err
+			}
+			word := toWord(a)
+			return word[:], nil
+
+		case REVERT:
+			return nil, ErrReverted
+		}
+
+		pc = next
+	}
+	return nil, nil
+}
+
+func (in *interpreter) contextWord(op OpCode) *big.Int {
+	switch op {
+	case CALLER:
+		return new(big.Int).SetBytes(in.ctx.Caller.ToSlice())
+	case ADDRESS:
+		return new(big.Int).SetBytes(in.ctx.Address.ToSlice())
+	case CALLVALUE:
+		return in.ctx.Value.ToBig()
+	case HEIGHT:
+		return big.NewInt(int64(in.ctx.Height))
+	case TIMESTAMP:
+		return big.NewInt(in.ctx.Timestamp)
+	default:
+		return big.NewInt(int64(len(in.ctx.Input)))
+	}
+}
+
+// binaryOp applies op to the two topmost stack items, a being the top one.
+// Division by zero results in zero.
+func binaryOp(op OpCode, a *big.Int, b *big.Int) *big.Int {
+	r := new(big.Int)
+	switch op {
+	case ADD:
+		return r.Add(a, b)
+	case SUB:
+		return r.Sub(a, b)
+	case MUL:
+		return r.Mul(a, b)
+	case DIV:
+		if b.Sign() == 0 {
+			return r
+		}
+		return r.Div(a, b)
+	case MOD:
+		if b.Sign() == 0 {
+			return r
+		}
+		return r.Mod(a, b)
+	case LT:
+		return boolWord(a.Cmp(b) < 0)
+	case GT:
+		return boolWord(a.Cmp(b) > 0)
+	case EQ:
+		return boolWord(a.Cmp(b) == 0)
+	case AND:
+		return r.And(a, b)
+	default:
+		return r.Or(a, b)
+	}
+}
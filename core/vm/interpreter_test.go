@@ -0,0 +1,127 @@
+package vm
+
+import (
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+type memoryState struct {
+	storage  map[common.Address]map[[WordSize]byte][WordSize]byte
+	balances map[common.Address]common.Amount
+}
+
+func newMemoryState() *memoryState {
+	return &memoryState{
+		storage:  make(map[common.Address]map[[WordSize]byte][WordSize]byte),
+		balances: make(map[common.Address]common.Amount),
+	}
+}
+
+func (s *memoryState) GetStorage(contract common.Address, key [WordSize]byte) ([WordSize]byte, error) {
+	return s.storage[contract][key], nil
+}
+
+func (s *memoryState) SetStorage(contract common.Address, key [WordSize]byte, value [WordSize]byte) error {
+	if s.storage[contract] == nil {
+		s.storage[contract] = make(map[[WordSize]byte][WordSize]byte)
+	}
+	s.storage[contract][key] = value
+	return nil
+}
+
+func (s *memoryState) GetBalance(address common.Address) (common.Amount, error) {
+	return s.balances[address], nil
+}
+
+func (s *memoryState) Transfer(from common.Address, to common.Address, amount common.Amount) error {
+	if s.balances[from].Lt(amount) {
+		return ErrInsufficientBalance
+	}
+	s.balances[from], _ = s.balances[from].Sub(amount)
+	s.balances[to], _ = s.balances[to].Add(amount)
+	return nil
+}
+
+func push(v byte) []byte {
+	return []byte{byte(PUSH), 1, v}
+}
+
+func word(output []byte) uint64 {
+	return new(big.Int).SetBytes(output).Uint64()
+}
+
+func TestRunArithmetic(t *testing.T) {
+	// (7 - 2) * 3
+	code := append(push(2), push(7)...)
+	code = append(code, byte(SUB))
+	code = append(code, push(3)...)
+	code = append(code, byte(MUL), byte(RETURN))
+
+	result, err := Run(code, Context{}, newMemoryState(), 100)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(15), word(result.Output))
+	assert.Equal(t, uint64(3*3+3+5), result.GasUsed)
+}
+
+func TestRunStorage(t *testing.T) {
+	contract := common.Address{1}
+	db := newMemoryState()
+
+	// storage[1] = calldata[0:32], return storage[1]
+	code := append(push(0), byte(CALLDATALOAD))
+	code = append(code, push(1)...)
+	code = append(code, byte(SSTORE))
+	code = append(code, push(1)...)
+	code = append(code, byte(SLOAD), byte(RETURN))
+
+	input := make([]byte, WordSize)
+	input[WordSize-1] = 42
+
+	result, err := Run(code, Context{Address: contract, Input: input}, db, 10000)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(42), word(result.Output))
+
+	_, err = Run(code, Context{Address: contract, Input: input, ReadOnly: true}, db, 10000)
+	assert.ErrorIs(t, err, ErrWriteProtection)
+}
+
+func TestRunOutOfGas(t *testing.T) {
+	// an endless loop
+	code := append(push(0), byte(JUMP))
+
+	result, err := Run(code, Context{}, newMemoryState(), 1000)
+	assert.ErrorIs(t, err, ErrOutOfGas)
+	assert.Equal(t, uint64(1000), result.GasUsed)
+}
+
+func TestRunTransfer(t *testing.T) {
+	contract := common.Address{1}
+	caller := common.Address{2}
+	db := newMemoryState()
+	db.balances[contract] = common.NewAmount(10)
+
+	// send the whole balance of the contract to the caller
+	code := []byte{byte(ADDRESS), byte(BALANCE), byte(CALLER), byte(TRANSFER), byte(STOP)}
+
+	_, err := Run(code, Context{Caller: caller, Address: contract}, db, 10000)
+	assert.Nil(t, err)
+	assert.Equal(t, common.NewAmount(0), db.balances[contract])
+	assert.Equal(t, common.NewAmount(10), db.balances[caller])
+
+	_, err = Run(code, Context{Caller: caller, Address: contract}, db, 10000)
+	assert.Nil(t, err)
+
+	// BALANCE of the caller is 10, the contract has nothing left to send
+	code = []byte{byte(CALLER), byte(BALANCE), byte(CALLER), byte(TRANSFER)}
+	_, err = Run(code, Context{Caller: caller, Address: contract}, db, 10000)
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+	assert.NotErrorIs(t, err, ErrState)
+
+	_, err = Run([]byte{byte(REVERT)}, Context{}, db, 10000)
+	assert.ErrorIs(t, err, ErrReverted)
+
+	_, err = Run([]byte{0xff}, Context{}, db, 10000)
+	assert.NotNil(t, err)
+}
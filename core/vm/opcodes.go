@@ -0,0 +1,66 @@
+package vm
+
+// OpCode is a single byte instruction of the virtual machine.
+type OpCode byte
+
+const (
+	STOP OpCode = 0x00
+	ADD  OpCode = 0x01
+	SUB  OpCode = 0x02
+	MUL  OpCode = 0x03
+	DIV  OpCode = 0x04
+	MOD  OpCode = 0x05
+
+	LT     OpCode = 0x10
+	GT     OpCode = 0x11
+	EQ     OpCode = 0x12
+	ISZERO OpCode = 0x13
+	AND    OpCode = 0x14
+	OR     OpCode = 0x15
+	NOT    OpCode = 0x16
+
+	// PUSH is followed by one byte with the length n (1 to 32) and n bytes of data.
+	PUSH OpCode = 0x20
+	POP  OpCode = 0x21
+	// DUP and SWAP are followed by one byte with the depth n (1 to 16).
+	DUP  OpCode = 0x22
+	SWAP OpCode = 0x23
+
+	CALLER       OpCode = 0x30
+	CALLVALUE    OpCode = 0x31
+	ADDRESS      OpCode = 0x32
+	BALANCE      OpCode = 0x33
+	HEIGHT       OpCode = 0x34
+	TIMESTAMP    OpCode = 0x35
+	CALLDATALOAD OpCode = 0x36
+	CALLDATASIZE OpCode = 0x37
+
+	SLOAD  OpCode = 0x40
+	SSTORE OpCode = 0x41
+
+	JUMP  OpCode = 0x50
+	JUMPI OpCode = 0x51
+
+	TRANSFER OpCode = 0x60
+
+	RETURN OpCode = 0x70
+	REVERT OpCode = 0x71
+)
+
+// gasCosts is the gas charged for each instruction. Instructions that are not listed
+// are invalid.
+var gasCosts = map[OpCode]uint64{
+	STOP: 0,
+	ADD:  3, SUB: 3, MUL: 5, DIV: 5, MOD: 5,
+	LT: 3, GT: 3, EQ: 3, ISZERO: 3, AND: 3, OR: 3, NOT: 3,
+	PUSH: 3, POP: 2, DUP: 3, SWAP: 3,
+	CALLER: 2, CALLVALUE: 2, ADDRESS: 2, BALANCE: 100, HEIGHT: 2, TIMESTAMP: 2,
+	CALLDATALOAD: 3, CALLDATASIZE: 2,
+	SLOAD:    100,
+	SSTORE:   1000,
+	JUMP:     8,
+	JUMPI:    10,
+	TRANSFER: 1000,
+	RETURN:   0,
+	REVERT:   0,
+}
@@ -0,0 +1,305 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"golang.org/x/crypto/scrypt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Version is the version of the key file format.
+const Version = 1
+
+const (
+	// StandardScryptN and StandardScryptP take about a second and 256 MB of memory to
+	// derive a key, which is what key files are written with.
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+
+	// LightScryptN and LightScryptP are cheap parameters for tests.
+	LightScryptN = 1 << 12
+	LightScryptP = 6
+
+	scryptR     = 8
+	scryptDKLen = 32
+
+	// minScryptN, maxScryptN and maxScryptP bound the work a key file can ask for when
+	// it is decrypted, from too little to protect the key to more than a node can afford.
+	minScryptN = 1 << 12
+	maxScryptN = 1 << 20
+	maxScryptP = 16
+
+	kdfScrypt    = "scrypt"
+	cipherAESGCM = "aes-256-gcm"
+	saltLength   = 32
+	fileSuffix   = ".json"
+)
+
+var ErrDecrypt = errors.New("could not decrypt key with the given password")
+
+// KeyFile is an encrypted private key. The key is encrypted with AES-256-GCM under a key
+// derived from the password with scrypt, and the address is authenticated with it.
+type KeyFile struct {
+	Version int        `json:"version"`
+	Address string     `json:"address"`
+	Crypto  CryptoJSON `json:"crypto"`
+}
+
+type CryptoJSON struct {
+	Cipher     string       `json:"cipher"`
+	CipherText string       `json:"ciphertext"`
+	Nonce      string       `json:"nonce"`
+	KDF        string       `json:"kdf"`
+	KDFParams  ScryptParams `json:"kdfparams"`
+}
+
+type ScryptParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+// Account is a key file in a keystore directory.
+type Account struct {
+	Address common.Address
+	Path    string
+}
+
+// EncryptKey encrypts the private key with the password into a key file.
+func EncryptKey(key *types.PrivateKey, password string, scryptN int, scryptP int) ([]byte, error) {
+	salt := make([]byte, saltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	derivedKey, err := scrypt.Key([]byte(password), salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(derivedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	address := key.PublicKey.Address()
+	cipherText := gcm.Seal(nil, nonce, key.Bytes(), address.ToSlice())
+
+	return json.MarshalIndent(KeyFile{
+		Version: Version,
+		Address: address.String(),
+		Crypto: CryptoJSON{
+			Cipher:     cipherAESGCM,
+			CipherText: hex.EncodeToString(cipherText),
+			Nonce:      hex.EncodeToString(nonce),
+			KDF:        kdfScrypt,
+			KDFParams: ScryptParams{
+				N:     scryptN,
+				R:     scryptR,
+				P:     scryptP,
+				DKLen: scryptDKLen,
+				Salt:  hex.EncodeToString(salt),
+			},
+		},
+	}, "", "  ")
+}
+
+// DecryptKey decrypts a key file with the password.
+func DecryptKey(data []byte, password string) (*types.PrivateKey, error) {
+	keyFile := KeyFile{}
+	if err := json.Unmarshal(data, &keyFile); err != nil {
+		return nil, fmt.Errorf("invalid key file: %w", err)
+	}
+
+	if keyFile.Version != Version {
+		return nil, fmt.Errorf("unsupported key file version %d", keyFile.Version)
+	}
+
+	c := keyFile.Crypto
+	if c.KDF != kdfScrypt || c.Cipher != cipherAESGCM {
+		return nil, fmt.Errorf("unsupported key file cipher %s with kdf %s", c.Cipher, c.KDF)
+	}
+
+	params := c.KDFParams
+	if params.N < minScryptN || params.N > maxScryptN || params.N&(params.N-1) != 0 ||
+		params.P < 1 || params.P > maxScryptP || params.R != scryptR || params.DKLen != scryptDKLen {
+		return nil, fmt.Errorf("unsupported scrypt parameters")
+	}
+
+	address, err := decodeAddress(keyFile.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %w", err)
+	}
+	nonce, err := hex.DecodeString(c.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce: %w", err)
+	}
+	cipherText, err := hex.DecodeString(c.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+
+	derivedKey, err := scrypt.Key([]byte(password), salt, params.N, params.R, params.P, params.DKLen)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(derivedKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length %d", len(nonce))
+	}
+
+	plainText, err := gcm.Open(nil, nonce, cipherText, address.ToSlice())
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	key, err := types.PrivateKeyFromBytes(plainText)
+	if err != nil {
+		return nil, err
+	}
+
+	if !key.PublicKey.Address().Equal(address) {
+		return nil, fmt.Errorf("key file holds the key of another address")
+	}
+	return key, nil
+}
+
+// Store encrypts the private key into a new key file in the directory and returns its path.
+func Store(dir string, key *types.PrivateKey, password string, scryptN int, scryptP int) (string, error) {
+	data, err := EncryptKey(key, password, scryptN, scryptP)
+	if err != nil {
+		return "", err
+	}
+
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, key.PublicKey.Address().String()+fileSuffix)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return "", fmt.Errorf("keystore already has a key for %s", key.PublicKey.Address())
+		}
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err = f.Write(data); err != nil {
+		return "", err
+	}
+	return path, f.Sync()
+}
+
+// Load reads and decrypts a key file.
+func Load(path string, password string) (*types.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecryptKey(data, password)
+}
+
+// List returns the key files in the directory ordered by address. A missing directory
+// has no keys.
+func List(dir string) ([]Account, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Account{}, nil
+		}
+		return nil, err
+	}
+
+	accounts := []Account{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileSuffix) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		keyFile := KeyFile{}
+		if err = json.Unmarshal(data, &keyFile); err != nil || keyFile.Version != Version {
+			continue
+		}
+
+		address, err := decodeAddress(keyFile.Address)
+		if err != nil {
+			continue
+		}
+		accounts = append(accounts, Account{Address: address, Path: path})
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Address.String() < accounts[j].Address.String()
+	})
+	return accounts, nil
+}
+
+// Find returns the key file of the address in the directory.
+func Find(dir string, address common.Address) (Account, error) {
+	accounts, err := List(dir)
+	if err != nil {
+		return Account{}, err
+	}
+
+	for _, account := range accounts {
+		if account.Address.Equal(address) {
+			return account, nil
+		}
+	}
+	return Account{}, fmt.Errorf("no key for %s in %s", address, dir)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func decodeAddress(s string) (common.Address, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid key file address %s", s)
+	}
+
+	address, err := common.NewAddressFromBytes(b)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid key file address %s", s)
+	}
+	return address, nil
+}
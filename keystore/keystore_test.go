@@ -0,0 +1,89 @@
+package keystore
+
+import (
+	"encoding/json"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncryptDecryptKey(t *testing.T) {
+	key := types.GeneratePrivateKey()
+
+	data, err := EncryptKey(key, "secret", LightScryptN, LightScryptP)
+	assert.Nil(t, err)
+
+	decrypted, err := DecryptKey(data, "secret")
+	assert.Nil(t, err)
+	assert.Equal(t, key.Bytes(), decrypted.Bytes())
+
+	_, err = DecryptKey(data, "wrong")
+	assert.Equal(t, ErrDecrypt, err)
+
+	// the address is authenticated with the key
+	keyFile := KeyFile{}
+	assert.Nil(t, json.Unmarshal(data, &keyFile))
+	keyFile.Address = types.GeneratePrivateKey().PublicKey.Address().String()
+	tampered, err := json.Marshal(keyFile)
+	assert.Nil(t, err)
+	_, err = DecryptKey(tampered, "secret")
+	assert.Equal(t, ErrDecrypt, err)
+
+	// a key file can not ask for unbounded or too little work
+	for _, params := range []struct{ n, p int }{
+		{maxScryptN * 2, LightScryptP},
+		{minScryptN / 2, LightScryptP},
+		{LightScryptN + 1, LightScryptP},
+		{LightScryptN, 0},
+		{LightScryptN, maxScryptP + 1},
+	} {
+		assert.Nil(t, json.Unmarshal(data, &keyFile))
+		keyFile.Crypto.KDFParams.N = params.n
+		keyFile.Crypto.KDFParams.P = params.p
+		tampered, err = json.Marshal(keyFile)
+		assert.Nil(t, err)
+		_, err = DecryptKey(tampered, "secret")
+		assert.NotNil(t, err, params)
+	}
+}
+
+func TestStoreListFind(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keystore")
+
+	accounts, err := List(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(accounts))
+
+	key := types.GeneratePrivateKey()
+	path, err := Store(dir, key, "secret", LightScryptN, LightScryptP)
+	assert.Nil(t, err)
+
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	_, err = Store(dir, key, "secret", LightScryptN, LightScryptP)
+	assert.NotNil(t, err)
+
+	other := types.GeneratePrivateKey()
+	_, err = Store(dir, other, "other", LightScryptN, LightScryptP)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "notes.json"), []byte("{}"), 0o600))
+
+	accounts, err = List(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(accounts))
+
+	account, err := Find(dir, key.PublicKey.Address())
+	assert.Nil(t, err)
+	assert.Equal(t, path, account.Path)
+
+	loaded, err := Load(account.Path, "secret")
+	assert.Nil(t, err)
+	assert.Equal(t, key.PublicKey.Address(), loaded.PublicKey.Address())
+
+	_, err = Find(dir, types.GeneratePrivateKey().PublicKey.Address())
+	assert.NotNil(t, err)
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
 	"github.com/barreleye-labs/barreleye/core/types"
 	"log"
+	"os"
 	"strings"
 	"time"
 
@@ -11,29 +13,45 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "account" {
+		if err := runAccountCommand(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "wallet" {
+		if err := runWalletCommand(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	common.ParseFlag()
 	nodeName := common.GetFlag("name")
 	port := common.GetFlag("port")
 	peers := common.GetFlag("peers")
 	httpPort := common.GetFlag("http.port")
-	key := common.GetFlag("key")
+
+	genesis, err := config.LoadGenesis(common.GetFlag("genesis"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	genesis.Apply()
 
 	peerArr := []string{}
 	if peers != "none" {
 		peerArr = strings.Split(peers, ",")
 	}
 
-	/* create hex key
-	nodePrivateKey := types.GeneratePrivateKey()
-	hexPrivateKey := hex.EncodeToString(crypto.FromECDSA(nodePrivateKey.Key))
-	privateKey1, _ := crypto.HexToECDSA(hexPrivateKey)
-	nodePrivateKey.Key = privateKey1
-	fmt.Println("pk: ", hexPrivateKey)
-	*/
+	keyFile := common.GetFlag("keyfile")
+	if keyFile == "" {
+		log.Fatal("the node key is required, create one with `barreleye account new` and pass its key file with -keyfile")
+	}
 
-	privateKey, err := types.CreatePrivateKey(key)
+	privateKey, err := loadKey(keyFile, common.GetFlag("password"))
 	if err != nil {
-		panic("failed to create private key")
+		log.Fatal(err)
 	}
 
 	n := createNode(nodeName, privateKey, ":"+port, peerArr, ":"+httpPort)
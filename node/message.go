@@ -9,8 +9,12 @@ type BlockRequestMessage struct {
 	Height int32
 }
 
+// BlockResponseMessage holds the requested block. A pruned node sets Pruned and the
+// Height of the request instead, so that the block is requested from another peer.
 type BlockResponseMessage struct {
-	Block *types.Block
+	Block  *types.Block
+	Height int32
+	Pruned bool
 }
 
 type ChainInfoRequestMessage struct {
@@ -6,10 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
 	"github.com/barreleye-labs/barreleye/core/types"
-	"math/rand"
 	"net"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
@@ -18,7 +19,8 @@ import (
 	"github.com/go-kit/log"
 )
 
-var defaultBlockTime = 7 * time.Second
+// slotCheckInterval is how often the node checks whether it is its turn to produce a block.
+var slotCheckInterval = 500 * time.Millisecond
 
 type NodeOpts struct {
 	APIListenAddr string
@@ -29,7 +31,6 @@ type NodeOpts struct {
 	Logger        log.Logger
 	RPCDecodeFunc RPCDecodeFunc
 	RPCProcessor  RPCProcessor
-	BlockTime     time.Duration
 	PrivateKey    *types.PrivateKey
 }
 
@@ -56,9 +57,6 @@ type Node struct {
 }
 
 func NewNode(opts NodeOpts) (*Node, error) {
-	if opts.BlockTime == time.Duration(0) {
-		opts.BlockTime = defaultBlockTime
-	}
 	if opts.RPCDecodeFunc == nil {
 		opts.RPCDecodeFunc = DecodeRPCDefaultFunc
 	}
@@ -67,7 +65,7 @@ func NewNode(opts NodeOpts) (*Node, error) {
 		opts.Logger = log.With(opts.Logger, "🕰", log.DefaultTimestampUTC)
 	}
 
-	chain, err := core.NewBlockchain(opts.Logger, opts.PrivateKey)
+	chain, err := core.NewBlockchain(opts.Logger)
 	if err != nil {
 		return nil, err
 	}
@@ -99,7 +97,7 @@ func NewNode(opts NodeOpts) (*Node, error) {
 		rpcCh:             make(chan RPC),
 		quitCh:            make(chan struct{}, 1),
 		txChan:            txChan,
-		miningTicker:      time.NewTicker(opts.BlockTime),
+		miningTicker:      time.NewTicker(slotCheckInterval),
 		miningStopped:     true,
 		miningRestartTime: 0,
 		isCheckingTimeout: false,
@@ -107,6 +105,14 @@ func NewNode(opts NodeOpts) (*Node, error) {
 
 	n.TCPTransport.peerCh = peerCh
 
+	lastHeight, err := chain.ReadLastBlockHeight()
+	if err != nil {
+		return nil, err
+	}
+	if lastHeight != nil {
+		n.txPool.SetNextHeight(*lastHeight + 1)
+	}
+
 	if n.RPCProcessor == nil {
 		n.RPCProcessor = n
 	}
@@ -156,7 +162,7 @@ free:
 		case peer := <-n.peerCh:
 			n.peerMap[peer.conn.RemoteAddr()] = peer
 
-			go peer.readLoop(n.rpcCh)
+			go peer.readLoop(n.rpcCh, n.Logger)
 
 			time.Sleep(5 * time.Second)
 
@@ -210,7 +216,7 @@ free:
 }
 
 func (n *Node) mine() {
-	_ = n.Logger.Log("msg", "start mining using POR(proof of random)", "blockTime", n.BlockTime)
+	_ = n.Logger.Log("msg", "start mining using slot-based producer schedule", "blockTime", config.BlockTime)
 
 	for {
 		//height, err := n.chain.ReadLastBlockHeight()
@@ -228,7 +234,17 @@ func (n *Node) mine() {
 			continue
 		}
 
-		if err := n.sealBlock(); err != nil {
+		due, err := n.isSlotDue()
+		if err != nil {
+			_ = n.Logger.Log("error", err)
+			continue
+		}
+
+		if !due {
+			continue
+		}
+
+		if err = n.sealBlock(); err != nil {
 			_ = n.Logger.Log("sealing block error", err)
 		}
 	}
@@ -260,15 +276,30 @@ func (n *Node) HandleMessage(msg *DecodedMessage) error {
 	return nil
 }
 
-func (n *Node) handleBlock(b *types.Block) error {
-	s := rand.NewSource(time.Now().UnixNano())
-	r := rand.New(s)
+// isSlotDue reports whether this node is allowed to produce the next block now.
+func (n *Node) isSlotDue() (bool, error) {
+	lastHeader, err := n.chain.ReadLastHeader()
+	if err != nil {
+		return false, err
+	}
+
+	if lastHeader == nil {
+		return false, nil
+	}
+
+	return n.chain.Schedule().IsDue(
+		lastHeader.Timestamp,
+		lastHeader.Height+1,
+		n.PrivateKey.PublicKey.Address(),
+		time.Now().UnixNano()), nil
+}
 
-	n.miningTicker.Reset(n.BlockTime + time.Duration(r.Intn(7))*time.Second)
+func (n *Node) handleBlock(b *types.Block) error {
 	if err := n.chain.LinkBlock(b); err != nil {
 		//_ = n.Logger.Log("error", err.Error())
 		return err
 	}
+	n.txPool.SetNextHeight(b.Height + 1)
 
 	go n.broadcastBlock(b)
 
@@ -280,7 +311,20 @@ func (n *Node) handleTransaction(tx *types.Transaction) error {
 		return err
 	}
 
-	if err := n.txPool.Add(tx, n.chain); err != nil {
+	if err := core.ValidateTransaction(tx); err != nil {
+		return err
+	}
+
+	policy, err := n.chain.ReadMultisigPolicy(tx.From)
+	if err != nil {
+		return err
+	}
+
+	if err = core.AuthorizeSender(policy, tx); err != nil {
+		return err
+	}
+
+	if err = n.txPool.Add(tx, n.chain); err != nil {
 		return err
 	}
 
@@ -333,17 +377,17 @@ func (n *Node) handleBlockHashRequestMessage(from net.Addr, data *BlockHashReque
 		return fmt.Errorf("requested block height %d is higher compared to block height %d in this chain", data.Height, height)
 	}
 
-	block, err := n.chain.ReadBlockByHeight(data.Height)
+	hash, err := n.chain.ReadBlockHashByHeight(data.Height)
 	if err != nil {
 		return err
 	}
 
-	if block == nil {
+	if hash == nil {
 		return fmt.Errorf("not found block")
 	}
 
 	blockHashResponseMsg := &BlockHashResponseMessage{
-		Hash:          block.Hash,
+		Hash:          *hash,
 		CurrentHeight: *height,
 	}
 
@@ -388,15 +432,12 @@ func (n *Node) handleBlockHashResponseMessage(from net.Addr, data *BlockHashResp
 		return nil
 	}
 
-	if err = n.chain.RemoveLastBlock(); err != nil {
-		return err
+	if lastBlock.Height == 0 {
+		return fmt.Errorf("peer %s has another genesis block, its genesis file differs from ours", from)
 	}
 
-	if lastBlock.Height < 1 {
-		if err = n.sendBlockRequestMessage(from, lastBlock.Height+1); err != nil {
-			return err
-		}
-		return nil
+	if err = n.chain.RemoveLastBlock(); err != nil {
+		return err
 	}
 
 	if err = n.sendBlockHashRequestMessage(from, lastBlock.Height-1); err != nil {
@@ -449,11 +490,21 @@ func (n *Node) handleBlockRequestMessage(from net.Addr, data *BlockRequestMessag
 		return err
 	}
 
+	response := &BlockResponseMessage{Block: block}
 	if block == nil {
-		return fmt.Errorf("not found block")
+		pruned, err := n.chain.IsPrunedHeight(data.Height)
+		if err != nil {
+			return err
+		}
+		if !pruned {
+			return fmt.Errorf("not found block")
+		}
+
+		// the requester cannot sync from a pruned node and asks another peer.
+		response = &BlockResponseMessage{Height: data.Height, Pruned: true}
 	}
 
-	if err = n.sendBlockResponseMessage(from, block); err != nil {
+	if err = n.sendBlockResponseMessage(from, response); err != nil {
 		return err
 	}
 
@@ -463,15 +514,11 @@ func (n *Node) handleBlockRequestMessage(from net.Addr, data *BlockRequestMessag
 	return nil
 }
 
-func (n *Node) sendBlockResponseMessage(from net.Addr, block *types.Block) error {
-	if block == nil {
+func (n *Node) sendBlockResponseMessage(from net.Addr, blockResponseMsg *BlockResponseMessage) error {
+	if blockResponseMsg.Block == nil && !blockResponseMsg.Pruned {
 		return fmt.Errorf("block is nil")
 	}
 
-	blockResponseMsg := &BlockResponseMessage{
-		Block: block,
-	}
-
 	buf := new(bytes.Buffer)
 	if err := gob.NewEncoder(buf).Encode(blockResponseMsg); err != nil {
 		return err
@@ -488,21 +535,31 @@ func (n *Node) sendBlockResponseMessage(from net.Addr, block *types.Block) error
 		return err
 	}
 
-	_ = n.Logger.Log("msg", "✉️ send block response message", "height", block.Height)
+	if blockResponseMsg.Pruned {
+		_ = n.Logger.Log("msg", "✉️ send block pruned message", "height", blockResponseMsg.Height)
+		return nil
+	}
+	_ = n.Logger.Log("msg", "✉️ send block response message", "height", blockResponseMsg.Block.Height)
 	return nil
 }
 
 func (n *Node) handleBlockResponseMessage(from net.Addr, data *BlockResponseMessage) error {
-	_ = n.Logger.Log("msg", "📦 received the requested block", "height:", data.Block.Height, "from", from)
+	if data.Pruned {
+		_ = n.Logger.Log("msg", "📦 peer has pruned the requested block", "height", data.Height, "from", from)
+		return n.requestBlockFromOtherPeer(from, data.Height)
+	}
 
 	if data.Block == nil {
 		return fmt.Errorf("no block in block response message")
 	}
 
+	_ = n.Logger.Log("msg", "📦 received the requested block", "height:", data.Block.Height, "from", from)
+
 	if err := n.chain.LinkBlock(data.Block); err != nil {
 		_ = n.Logger.Log("error", err.Error())
 		return err
 	}
+	n.txPool.SetNextHeight(data.Block.Height + 1)
 
 	if n.peersBlockHeightUntilSync > data.Block.Height {
 		if err := n.sendBlockRequestMessage(from, data.Block.Height+1); err != nil {
@@ -517,6 +574,25 @@ func (n *Node) handleBlockResponseMessage(from net.Addr, data *BlockResponseMess
 	return nil
 }
 
+// requestBlockFromOtherPeer requests the block of the height from a peer other than the
+// pruned peer, which cannot serve the block.
+func (n *Node) requestBlockFromOtherPeer(pruned net.Addr, height int32) error {
+	n.mu.RLock()
+	var other net.Addr
+	for addr := range n.peerMap {
+		if addr.String() != pruned.String() {
+			other = addr
+			break
+		}
+	}
+	n.mu.RUnlock()
+
+	if other == nil {
+		return fmt.Errorf("%w: no other peer to sync block %d from than %s", common.ErrBlockPruned, height, pruned)
+	}
+	return n.sendBlockRequestMessage(other, height)
+}
+
 func (n *Node) sendChainInfoRequestMessage(from net.Addr) error {
 	var (
 		getStatusMsg = new(ChainInfoRequestMessage)
@@ -638,6 +714,60 @@ func (n *Node) broadcastTx(tx *types.Transaction) error {
 	return n.broadcast(msg.Bytes())
 }
 
+// fillBlock takes transactions in the given order while they fit in the count, size and
+// gas limits of a block, skipping the ones too large for the space left.
+func fillBlock(txs []*types.Transaction) []*types.Transaction {
+	picked := []*types.Transaction{}
+	size := 0
+	gas := uint64(0)
+	for _, tx := range txs {
+		if len(picked) == config.MaxBlockTxs {
+			break
+		}
+
+		txSize := tx.Size()
+		txGas := core.TxGas(tx)
+		if size+txSize > config.MaxBlockSize || gas+txGas > config.MaxBlockGas {
+			continue
+		}
+
+		picked = append(picked, tx)
+		size += txSize
+		gas += txGas
+	}
+	return picked
+}
+
+// finalizeBlock builds the next block from the transactions. Transactions that the
+// chain refuses to include are dropped from the pool and the block is built without them.
+func (n *Node) finalizeBlock(lastHeader *types.Header, txs []*types.Transaction) (*types.Block, error) {
+	for {
+		block, err := types.NewBlockFromPrevHeader(lastHeader, fillBlock(txs))
+		if err != nil {
+			return nil, err
+		}
+
+		err = n.chain.FinalizeBlock(block, n.PrivateKey.PublicKey.Address())
+		if err == nil {
+			return block, nil
+		}
+
+		var txErr *types.BlockTxError
+		if !errors.As(err, &txErr) {
+			return nil, err
+		}
+
+		_ = n.Logger.Log("msg", "drop transaction", "hash", txErr.Hash, "reason", txErr.Err)
+		n.txPool.Remove(txErr.Hash)
+		for i, tx := range txs {
+			if tx.GetHash().Equal(txErr.Hash) {
+				txs = append(txs[:i], txs[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
 func (n *Node) sealBlock() error {
 	lastHeader, err := n.chain.ReadLastHeader()
 	if err != nil {
@@ -648,7 +778,8 @@ func (n *Node) sealBlock() error {
 		return fmt.Errorf("can not seal the block without genesis block")
 	}
 
-	txs := n.txPool.Pending()
+	n.txPool.SetNextHeight(lastHeader.Height + 1)
+	txs := append([]*types.Transaction{}, n.txPool.Pending()...)
 
 	for i := 0; i < len(txs); i++ {
 		txProcessed, err := n.chain.ReadTxByHash(txs[i].Hash)
@@ -680,7 +811,11 @@ func (n *Node) sealBlock() error {
 		}
 	}
 
-	block, err := types.NewBlockFromPrevHeader(lastHeader, txs)
+	sort.SliceStable(txs, func(i, j int) bool {
+		return txs[i].Fee.Gt(txs[j].Fee)
+	})
+
+	block, err := n.finalizeBlock(lastHeader, txs)
 	if err != nil {
 		return err
 	}
@@ -695,7 +830,11 @@ func (n *Node) sealBlock() error {
 		return err
 	}
 
-	n.txPool.ClearPending()
+	// transactions that did not fit into the block or wait for an earlier nonce stay
+	// pending for the next one.
+	for _, tx := range block.Transactions {
+		n.txPool.Remove(tx.GetHash())
+	}
 
 	go n.broadcastBlock(block)
 
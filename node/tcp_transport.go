@@ -2,11 +2,20 @@ package node
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
+	"github.com/barreleye-labs/barreleye/config"
+	"github.com/go-kit/log"
 	"io"
 	"net"
 )
 
+const messageHeaderSize = 4
+
+// maxMessageSize is the largest message read from a peer, a block of the largest size
+// with room for its header, signature and the message envelope.
+var maxMessageSize = uint32(config.MaxBlockSize + 64*1024)
+
 type TCPPeer struct {
 	conn     net.Conn
 	Outgoing bool
@@ -17,24 +26,36 @@ func (p *TCPPeer) Close() error {
 	return err
 }
 
+// Send writes the message with its length in front, so that the reader can tell where it ends.
 func (p *TCPPeer) Send(b []byte) error {
-	_, err := p.conn.Write(b)
+	frame := binary.BigEndian.AppendUint32(make([]byte, 0, messageHeaderSize+len(b)), uint32(len(b)))
+	_, err := p.conn.Write(append(frame, b...))
 	return err
 }
 
-func (p *TCPPeer) readLoop(rpcCh chan RPC) {
-	buf := make([]byte, 4096)
+// readLoop reads the messages of the peer until the connection fails. A peer sending a
+// message larger than maxMessageSize is disconnected without reading the message.
+func (p *TCPPeer) readLoop(rpcCh chan RPC, logger log.Logger) {
+	header := make([]byte, messageHeaderSize)
 	for {
-		n, err := p.conn.Read(buf)
-		if err == io.EOF {
-			continue
+		if _, err := io.ReadFull(p.conn, header); err != nil {
+			_ = logger.Log("msg", "communication with peer has been lost", "peer", p.conn.RemoteAddr(), "err", err)
+			return
 		}
-		if err != nil {
-			fmt.Printf("communication with peer has been lost and will no longer be received.\nread error: %s", err)
+
+		size := binary.BigEndian.Uint32(header)
+		if size > maxMessageSize {
+			_ = logger.Log("msg", "peer sent a message larger than the limit, disconnecting", "peer", p.conn.RemoteAddr(), "size", size, "limit", maxMessageSize)
+			_ = p.conn.Close()
+			return
+		}
+
+		msg := make([]byte, size)
+		if _, err := io.ReadFull(p.conn, msg); err != nil {
+			_ = logger.Log("msg", "communication with peer has been lost", "peer", p.conn.RemoteAddr(), "err", err)
 			return
 		}
 
-		msg := buf[:n]
 		rpcCh <- RPC{
 			From:    p.conn.RemoteAddr(),
 			Payload: bytes.NewReader(msg),
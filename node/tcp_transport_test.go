@@ -0,0 +1,53 @@
+package node
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTCPPeerFraming(t *testing.T) {
+	local, remote := net.Pipe()
+	sender := &TCPPeer{conn: local}
+	receiver := &TCPPeer{conn: remote}
+
+	rpcCh := make(chan RPC)
+	go receiver.readLoop(rpcCh, log.NewNopLogger())
+
+	large := make([]byte, 100_000)
+	large[len(large)-1] = 7
+	go func() {
+		assert.Nil(t, sender.Send([]byte("first")))
+		assert.Nil(t, sender.Send(large))
+	}()
+
+	first, err := io.ReadAll((<-rpcCh).Payload)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("first"), first)
+
+	second, err := io.ReadAll((<-rpcCh).Payload)
+	assert.Nil(t, err)
+	assert.Equal(t, large, second)
+}
+
+func TestTCPPeerRejectsOversizedMessage(t *testing.T) {
+	local, remote := net.Pipe()
+	receiver := &TCPPeer{conn: remote}
+
+	done := make(chan struct{})
+	go func() {
+		receiver.readLoop(make(chan RPC), log.NewNopLogger())
+		close(done)
+	}()
+
+	_, err := local.Write(binary.BigEndian.AppendUint32(nil, maxMessageSize+1))
+	assert.Nil(t, err)
+	<-done
+
+	_, err = local.Write([]byte{0})
+	assert.NotNil(t, err)
+}
@@ -3,6 +3,7 @@ package node
 import (
 	"fmt"
 	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
 	"github.com/barreleye-labs/barreleye/core"
 	"github.com/barreleye-labs/barreleye/core/types"
 	"sync"
@@ -11,6 +12,9 @@ import (
 type TxPool struct {
 	pending   *TxSortedMap
 	maxLength int
+
+	// nextHeight is the height of the next block, transactions expiring before it are dropped.
+	nextHeight int32
 }
 
 func NewTxPool(maxLength int) *TxPool {
@@ -25,6 +29,18 @@ func (p *TxPool) Add(tx *types.Transaction, chain *core.Blockchain) error {
 		return common.ErrTransactionAlreadyPending
 	}
 
+	if tx.Fee.Lt(config.MinTxFee) {
+		return common.ErrTxFeeTooLow
+	}
+
+	if tx.IsExpired(p.nextHeight) {
+		return common.ErrTxExpired
+	}
+
+	if err := core.ValidateTxSize(tx); err != nil {
+		return err
+	}
+
 	txs := p.Pending()
 	for i := 0; i < len(txs); i++ {
 		if txs[i].From == tx.From {
@@ -42,15 +58,32 @@ func (p *TxPool) Add(tx *types.Transaction, chain *core.Blockchain) error {
 		}
 	}
 
+	// a full pool makes room by dropping the transaction paying the lowest fee.
 	if p.pending.Count() == p.maxLength {
-		oldest := p.pending.First()
-		p.pending.Remove(oldest.GetHash())
+		cheapest := p.pending.Cheapest()
+		if !tx.Fee.Gt(cheapest.Fee) {
+			return common.ErrTxFeeTooLow
+		}
+		p.pending.Remove(cheapest.GetHash())
 	}
 	p.pending.Add(tx)
 
 	return nil
 }
 
+// SetNextHeight sets the height of the next block and evicts the transactions that
+// can not be executed in it anymore.
+func (p *TxPool) SetNextHeight(height int32) {
+	p.nextHeight = height
+
+	txs := append([]*types.Transaction{}, p.Pending()...)
+	for _, tx := range txs {
+		if tx.IsExpired(height) {
+			p.pending.Remove(tx.GetHash())
+		}
+	}
+}
+
 func (p *TxPool) Contains(hash common.Hash) bool {
 	return p.pending.Contains(hash)
 }
@@ -59,6 +92,13 @@ func (p *TxPool) Pending() []*types.Transaction {
 	return p.pending.txs.Data
 }
 
+// Remove drops the transaction from the pool if it is pending.
+func (p *TxPool) Remove(hash common.Hash) {
+	if p.pending.Contains(hash) {
+		p.pending.Remove(hash)
+	}
+}
+
 func (p *TxPool) ClearPending() {
 	p.pending.Clear()
 }
@@ -88,6 +128,21 @@ func (t *TxSortedMap) First() *types.Transaction {
 	return t.lookup[first.GetHash()]
 }
 
+// Cheapest returns the oldest of the transactions paying the lowest fee.
+func (t *TxSortedMap) Cheapest() *types.Transaction {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	var cheapest *types.Transaction
+	for i := 0; i < t.txs.Len(); i++ {
+		tx := t.txs.Get(i)
+		if cheapest == nil || tx.Fee.Lt(cheapest.Fee) {
+			cheapest = tx
+		}
+	}
+	return cheapest
+}
+
 func (t *TxSortedMap) Get(h common.Hash) *types.Transaction {
 	t.lock.RLock()
 	defer t.lock.RUnlock()
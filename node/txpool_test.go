@@ -1,6 +1,8 @@
 package node
 
 import (
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/config"
 	"github.com/barreleye-labs/barreleye/core/types"
 	"testing"
 
@@ -57,3 +59,99 @@ func TestTxSortedMapRemove(t *testing.T) {
 	assert.Equal(t, m.Count(), 0)
 	assert.False(t, m.Contains(tx.GetHash()))
 }
+
+func TestTxPoolEvictsCheapest(t *testing.T) {
+	p := NewTxPool(2)
+
+	newTx := func(fee uint64) *types.Transaction {
+		tx := types.NewRandomTransaction(types.GeneratePrivateKey())
+		tx.Fee = common.CoinAmount(fee, config.Decimals)
+		return tx
+	}
+
+	cheap := newTx(1)
+	expensive := newTx(5)
+	assert.Nil(t, p.Add(cheap, nil))
+	assert.Nil(t, p.Add(expensive, nil))
+
+	assert.NotNil(t, p.Add(newTx(0), nil))
+	assert.NotNil(t, p.Add(newTx(1), nil))
+
+	assert.Nil(t, p.Add(newTx(2), nil))
+	assert.Equal(t, 2, p.PendingCount())
+	assert.False(t, p.Contains(cheap.GetHash()))
+	assert.True(t, p.Contains(expensive.GetHash()))
+}
+
+func TestTxPoolExpiry(t *testing.T) {
+	p := NewTxPool(10)
+	p.SetNextHeight(5)
+
+	newTx := func(validUntil int32) *types.Transaction {
+		tx := types.NewRandomTransaction(types.GeneratePrivateKey())
+		tx.Fee = config.MinTxFee
+		tx.ValidUntil = validUntil
+		return tx
+	}
+
+	expired := newTx(4)
+	assert.NotNil(t, p.Add(expired, nil))
+
+	expiring := newTx(5)
+	assert.Nil(t, p.Add(expiring, nil))
+
+	forever := newTx(0)
+	assert.Nil(t, p.Add(forever, nil))
+
+	p.SetNextHeight(6)
+	assert.False(t, p.Contains(expiring.GetHash()))
+	assert.True(t, p.Contains(forever.GetHash()))
+}
+
+func TestTxPoolRemove(t *testing.T) {
+	p := NewTxPool(10)
+
+	txs := []*types.Transaction{}
+	for i := 0; i < 3; i++ {
+		tx := types.NewRandomTransaction(types.GeneratePrivateKey())
+		tx.Fee = config.MinTxFee
+		assert.Nil(t, p.Add(tx, nil))
+		txs = append(txs, tx)
+	}
+
+	p.Remove(txs[1].GetHash())
+	p.Remove(types.RandomHash())
+	assert.Equal(t, 2, p.PendingCount())
+	assert.True(t, p.Contains(txs[0].GetHash()))
+	assert.False(t, p.Contains(txs[1].GetHash()))
+	assert.True(t, p.Contains(txs[2].GetHash()))
+}
+
+func TestFillBlock(t *testing.T) {
+	newTx := func(dataSize int) *types.Transaction {
+		tx := types.NewRandomTransaction(types.GeneratePrivateKey())
+		tx.Data = make([]byte, dataSize)
+		return tx
+	}
+
+	txs := []*types.Transaction{}
+	for i := 0; i < config.MaxBlockTxs+10; i++ {
+		txs = append(txs, newTx(0))
+	}
+	assert.Equal(t, config.MaxBlockTxs, len(fillBlock(txs)))
+
+	large := []*types.Transaction{}
+	for i := 0; i*config.MaxTxDataSize <= config.MaxBlockSize; i++ {
+		large = append(large, newTx(config.MaxTxDataSize))
+	}
+	small := newTx(0)
+	picked := fillBlock(append(large, small))
+
+	size := 0
+	for _, tx := range picked {
+		size += tx.Size()
+	}
+	assert.LessOrEqual(t, size, config.MaxBlockSize)
+	assert.Less(t, len(picked), len(large))
+	assert.Equal(t, small, picked[len(picked)-1])
+}
@@ -9,9 +9,19 @@ func (s *Server) Start() error {
 	e.GET("/blocks", s.getBlocks)
 	e.GET("/last-block", s.getLastBlock)
 	e.GET("txs/:id", s.getTx)
+	e.GET("txs/:id/proof", s.getTxProof)
 	e.GET("txs", s.getTxs)
 	e.GET("/accounts/:address", s.getAccount)
+	e.GET("/accounts/:address/tokens", s.getAccountTokens)
+	e.GET("/accounts/:address/multisig", s.getMultisig)
+	e.GET("/accounts/:address/locks", s.getAccountLocks)
+	e.GET("/tokens/:symbol", s.getToken)
+	e.GET("/contracts/:address", s.getContract)
+	e.GET("/chain", s.getChain)
+	e.GET("/supply", s.getSupply)
+	e.GET("/forks", s.getForks)
 	e.POST("/txs", s.postTx)
+	e.POST("/contracts/:address/call", s.callContract)
 	e.POST("/faucet", s.requestSomeCoin)
 
 	return e.Start(s.ListenAddr)
@@ -1,11 +1,39 @@
 package dto
 
+// Account holds the balances in the smallest unit as hex, and formatted in whole coins.
 type Account struct {
-	Address string `json:"address"`
-	Nonce   string `json:"nonce"`
-	Balance string `json:"balance"`
+	Address          string `json:"address"`
+	Nonce            string `json:"nonce"`
+	Balance          string `json:"balance"`
+	Locked           string `json:"locked"`
+	FormattedBalance string `json:"formattedBalance"`
+	FormattedLocked  string `json:"formattedLocked"`
 }
 
 type AccountResponse struct {
 	Account Account `json:"account"`
 }
+
+type Multisig struct {
+	Address   string   `json:"address"`
+	Threshold uint8    `json:"threshold"`
+	Signers   []string `json:"signers"`
+}
+
+func CreateMultisig(address string, threshold uint8, signers []string) Multisig {
+	return Multisig{
+		Address:   address,
+		Threshold: threshold,
+		Signers:   signers,
+	}
+}
+
+type MultisigResponse struct {
+	Multisig Multisig `json:"multisig"`
+}
+
+func CreateMultisigResponse(multisig Multisig) MultisigResponse {
+	return MultisigResponse{
+		Multisig: multisig,
+	}
+}
@@ -4,6 +4,7 @@ type Block struct {
 	Hash          string    `json:"hash"`
 	Version       uint32    `json:"version"`
 	DataHash      string    `json:"dataHash"`
+	StateRoot     string    `json:"stateRoot"`
 	PrevBlockHash string    `json:"prevBlockHash"`
 	Height        int32     `json:"height"`
 	Timestamp     int64     `json:"timestamp"`
@@ -18,6 +19,7 @@ func CreateBlock(
 	hash string,
 	version uint32,
 	dataHash string,
+	stateRoot string,
 	prevBlockHash string,
 	height int32,
 	timestamp int64,
@@ -30,6 +32,7 @@ func CreateBlock(
 		Hash:          hash,
 		Version:       version,
 		DataHash:      dataHash,
+		StateRoot:     stateRoot,
 		PrevBlockHash: prevBlockHash,
 		Height:        height,
 		Timestamp:     timestamp,
@@ -0,0 +1,29 @@
+package dto
+
+type Chain struct {
+	ChainID     string `json:"chainId"`
+	GenesisHash string `json:"genesisHash"`
+	Height      int32  `json:"height"`
+	Symbol      string `json:"symbol"`
+	Decimals    uint8  `json:"decimals"`
+}
+
+func CreateChain(chainID string, genesisHash string, height int32, symbol string, decimals uint8) Chain {
+	return Chain{
+		ChainID:     chainID,
+		GenesisHash: genesisHash,
+		Height:      height,
+		Symbol:      symbol,
+		Decimals:    decimals,
+	}
+}
+
+type ChainResponse struct {
+	Chain Chain `json:"chain"`
+}
+
+func CreateChainResponse(chain Chain) ChainResponse {
+	return ChainResponse{
+		Chain: chain,
+	}
+}
@@ -0,0 +1,30 @@
+package dto
+
+type ContractResponse struct {
+	Address string `json:"address"`
+	Code    string `json:"code"`
+}
+
+func CreateContractResponse(address string, code string) ContractResponse {
+	return ContractResponse{
+		Address: address,
+		Code:    code,
+	}
+}
+
+type CallContractRequest struct {
+	From string `json:"from"`
+	Data string `json:"data"`
+}
+
+type CallContractResponse struct {
+	Output  string `json:"output"`
+	GasUsed string `json:"gasUsed"`
+}
+
+func CreateCallContractResponse(output string, gasUsed string) CallContractResponse {
+	return CallContractResponse{
+		Output:  output,
+		GasUsed: gasUsed,
+	}
+}
@@ -0,0 +1,29 @@
+package dto
+
+type Fork struct {
+	Name   string `json:"name"`
+	Height int32  `json:"height"`
+	Active bool   `json:"active"`
+}
+
+func CreateFork(name string, height int32, active bool) Fork {
+	return Fork{
+		Name:   name,
+		Height: height,
+		Active: active,
+	}
+}
+
+type ForksResponse struct {
+	Forks         []Fork `json:"forks"`
+	BlockVersion  uint32 `json:"blockVersion"`
+	CurrentHeight int32  `json:"currentHeight"`
+}
+
+func CreateForksResponse(forks []Fork, blockVersion uint32, currentHeight int32) ForksResponse {
+	return ForksResponse{
+		Forks:         forks,
+		BlockVersion:  blockVersion,
+		CurrentHeight: currentHeight,
+	}
+}
@@ -0,0 +1,31 @@
+package dto
+
+type Lock struct {
+	TxHash          string `json:"txHash"`
+	Amount          string `json:"amount"`
+	UnlockHeight    int32  `json:"unlockHeight"`
+	UnlockTime      int64  `json:"unlockTime"`
+	FormattedAmount string `json:"formattedAmount"`
+}
+
+func CreateLock(txHash string, amount string, unlockHeight int32, unlockTime int64, formattedAmount string) Lock {
+	return Lock{
+		TxHash:          txHash,
+		Amount:          amount,
+		UnlockHeight:    unlockHeight,
+		UnlockTime:      unlockTime,
+		FormattedAmount: formattedAmount,
+	}
+}
+
+type LocksResponse struct {
+	Address string `json:"address"`
+	Locks   []Lock `json:"locks"`
+}
+
+func CreateLocksResponse(address string, locks []Lock) LocksResponse {
+	return LocksResponse{
+		Address: address,
+		Locks:   locks,
+	}
+}
@@ -0,0 +1,46 @@
+package dto
+
+type ProofNode struct {
+	Hash     string `json:"hash"`
+	Position string `json:"position"`
+}
+
+func CreateProofNode(hash string, position string) ProofNode {
+	return ProofNode{
+		Hash:     hash,
+		Position: position,
+	}
+}
+
+type TxProof struct {
+	TxHash      string      `json:"txHash"`
+	BlockHash   string      `json:"blockHash"`
+	BlockHeight int32       `json:"blockHeight"`
+	DataHash    string      `json:"dataHash"`
+	Path        []ProofNode `json:"path"`
+}
+
+func CreateTxProof(
+	txHash string,
+	blockHash string,
+	blockHeight int32,
+	dataHash string,
+	path []ProofNode) TxProof {
+	return TxProof{
+		TxHash:      txHash,
+		BlockHash:   blockHash,
+		BlockHeight: blockHeight,
+		DataHash:    dataHash,
+		Path:        path,
+	}
+}
+
+type TxProofResponse struct {
+	Proof TxProof `json:"proof"`
+}
+
+func CreateTxProofResponse(proof TxProof) TxProofResponse {
+	return TxProofResponse{
+		Proof: proof,
+	}
+}
@@ -0,0 +1,56 @@
+package dto
+
+type BalanceChange struct {
+	Address         string `json:"address"`
+	Before          string `json:"before"`
+	After           string `json:"after"`
+	FormattedBefore string `json:"formattedBefore"`
+	FormattedAfter  string `json:"formattedAfter"`
+}
+
+func CreateBalanceChange(address string, before string, after string, formattedBefore string, formattedAfter string) BalanceChange {
+	return BalanceChange{
+		Address:         address,
+		Before:          before,
+		After:           after,
+		FormattedBefore: formattedBefore,
+		FormattedAfter:  formattedAfter,
+	}
+}
+
+type Receipt struct {
+	Status         string          `json:"status"`
+	ErrorCode      uint16          `json:"errorCode"`
+	Error          string          `json:"error"`
+	BlockHash      string          `json:"blockHash"`
+	BlockHeight    int32           `json:"blockHeight"`
+	Index          uint32          `json:"index"`
+	BalanceChanges []BalanceChange `json:"balanceChanges"`
+
+	GasUsed         string `json:"gasUsed"`
+	ContractAddress string `json:"contractAddress,omitempty"`
+}
+
+func CreateReceipt(
+	status string,
+	errorCode uint16,
+	errorMessage string,
+	blockHash string,
+	blockHeight int32,
+	index uint32,
+	balanceChanges []BalanceChange,
+	gasUsed string,
+	contractAddress string) *Receipt {
+	return &Receipt{
+		Status:         status,
+		ErrorCode:      errorCode,
+		Error:          errorMessage,
+		BlockHash:      blockHash,
+		BlockHeight:    blockHeight,
+		Index:          index,
+		BalanceChanges: balanceChanges,
+
+		GasUsed:         gasUsed,
+		ContractAddress: contractAddress,
+	}
+}
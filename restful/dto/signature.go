@@ -1,13 +1,31 @@
 package dto
 
+// Signature is a recoverable signature. Compact is R, S and V as one hex string, the
+// format transactions are posted with.
 type Signature struct {
-	R string `json:"r"`
-	S string `json:"s"`
+	R       string `json:"r"`
+	S       string `json:"s"`
+	V       uint8  `json:"v"`
+	Compact string `json:"compact"`
 }
 
-func CreateSignature(r string, s string) Signature {
+func CreateSignature(r string, s string, v uint8, compact string) Signature {
 	return Signature{
-		R: r,
-		S: s,
+		R:       r,
+		S:       s,
+		V:       v,
+		Compact: compact,
+	}
+}
+
+type Cosignature struct {
+	Signer    string    `json:"signer"`
+	Signature Signature `json:"signature"`
+}
+
+func CreateCosignature(signer string, signature Signature) Cosignature {
+	return Cosignature{
+		Signer:    signer,
+		Signature: signature,
 	}
 }
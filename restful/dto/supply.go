@@ -0,0 +1,42 @@
+package dto
+
+type Supply struct {
+	CirculatingSupply string `json:"circulatingSupply"`
+	MaxSupply         string `json:"maxSupply"`
+	BlockReward       string `json:"blockReward"`
+	Height            int32  `json:"height"`
+
+	FormattedCirculatingSupply string `json:"formattedCirculatingSupply"`
+	FormattedMaxSupply         string `json:"formattedMaxSupply"`
+	FormattedBlockReward       string `json:"formattedBlockReward"`
+}
+
+func CreateSupply(
+	circulatingSupply string,
+	maxSupply string,
+	blockReward string,
+	height int32,
+	formattedCirculatingSupply string,
+	formattedMaxSupply string,
+	formattedBlockReward string) Supply {
+	return Supply{
+		CirculatingSupply: circulatingSupply,
+		MaxSupply:         maxSupply,
+		BlockReward:       blockReward,
+		Height:            height,
+
+		FormattedCirculatingSupply: formattedCirculatingSupply,
+		FormattedMaxSupply:         formattedMaxSupply,
+		FormattedBlockReward:       formattedBlockReward,
+	}
+}
+
+type SupplyResponse struct {
+	Supply Supply `json:"supply"`
+}
+
+func CreateSupplyResponse(supply Supply) SupplyResponse {
+	return SupplyResponse{
+		Supply: supply,
+	}
+}
@@ -0,0 +1,59 @@
+package dto
+
+type Token struct {
+	Symbol          string `json:"symbol"`
+	Name            string `json:"name"`
+	Owner           string `json:"owner"`
+	Supply          string `json:"supply"`
+	Decimals        uint8  `json:"decimals"`
+	Mintable        bool   `json:"mintable"`
+	FormattedSupply string `json:"formattedSupply"`
+}
+
+func CreateToken(symbol string, name string, owner string, supply string, decimals uint8, mintable bool, formattedSupply string) Token {
+	return Token{
+		Symbol:          symbol,
+		Name:            name,
+		Owner:           owner,
+		Supply:          supply,
+		Decimals:        decimals,
+		Mintable:        mintable,
+		FormattedSupply: formattedSupply,
+	}
+}
+
+type TokenResponse struct {
+	Token Token `json:"token"`
+}
+
+func CreateTokenResponse(token Token) TokenResponse {
+	return TokenResponse{
+		Token: token,
+	}
+}
+
+type TokenBalance struct {
+	Symbol           string `json:"symbol"`
+	Balance          string `json:"balance"`
+	FormattedBalance string `json:"formattedBalance"`
+}
+
+func CreateTokenBalance(symbol string, balance string, formattedBalance string) TokenBalance {
+	return TokenBalance{
+		Symbol:           symbol,
+		Balance:          balance,
+		FormattedBalance: formattedBalance,
+	}
+}
+
+type TokenBalancesResponse struct {
+	Address  string         `json:"address"`
+	Balances []TokenBalance `json:"balances"`
+}
+
+func CreateTokenBalancesResponse(address string, balances []TokenBalance) TokenBalancesResponse {
+	return TokenBalancesResponse{
+		Address:  address,
+		Balances: balances,
+	}
+}
@@ -1,19 +1,34 @@
 package dto
 
 type Transaction struct {
-	Hash        string    `json:"hash"`
-	Nonce       string    `json:"nonce"`
-	BlockHeight int32     `json:"blockHeight"`
-	Timestamp   int64     `json:"timestamp"`
-	From        string    `json:"from"`
-	To          string    `json:"to"`
-	Value       string    `json:"value"`
-	Data        string    `json:"data"`
-	Signer      Signer    `json:"signer"`
-	Signature   Signature `json:"signature"`
+	Type        uint8  `json:"type"`
+	ChainID     string `json:"chainId"`
+	Hash        string `json:"hash"`
+	Nonce       string `json:"nonce"`
+	BlockHeight int32  `json:"blockHeight"`
+	Timestamp   int64  `json:"timestamp"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Value       string `json:"value"`
+	Fee         string `json:"fee"`
+	Data        string `json:"data"`
+
+	FormattedValue string `json:"formattedValue"`
+	FormattedFee   string `json:"formattedFee"`
+
+	UnlockHeight int32 `json:"unlockHeight"`
+	UnlockTime   int64 `json:"unlockTime"`
+	ValidUntil   int32 `json:"validUntil"`
+
+	Signer    string    `json:"signer"`
+	Signature Signature `json:"signature"`
+
+	Cosignatures []Cosignature `json:"cosignatures"`
 }
 
 func CreateTransaction(
+	txType uint8,
+	chainID string,
 	hash string,
 	nonce string,
 	blockHeight int32,
@@ -21,10 +36,19 @@ func CreateTransaction(
 	from string,
 	to string,
 	value string,
+	fee string,
 	data string,
-	signer Signer,
-	signature Signature) Transaction {
+	formattedValue string,
+	formattedFee string,
+	unlockHeight int32,
+	unlockTime int64,
+	validUntil int32,
+	signer string,
+	signature Signature,
+	cosignatures []Cosignature) Transaction {
 	return Transaction{
+		Type:        txType,
+		ChainID:     chainID,
 		Hash:        hash,
 		Nonce:       nonce,
 		BlockHeight: blockHeight,
@@ -32,14 +56,26 @@ func CreateTransaction(
 		From:        from,
 		To:          to,
 		Value:       value,
+		Fee:         fee,
 		Data:        data,
-		Signer:      signer,
-		Signature:   signature,
+
+		FormattedValue: formattedValue,
+		FormattedFee:   formattedFee,
+
+		UnlockHeight: unlockHeight,
+		UnlockTime:   unlockTime,
+		ValidUntil:   validUntil,
+
+		Signer:    signer,
+		Signature: signature,
+
+		Cosignatures: cosignatures,
 	}
 }
 
 type TransactionResponse struct {
 	Transaction Transaction `json:"transaction"`
+	Receipt     *Receipt    `json:"receipt,omitempty"`
 }
 
 func CreateTransactionResponse(transaction Transaction) TransactionResponse {
@@ -48,6 +84,13 @@ func CreateTransactionResponse(transaction Transaction) TransactionResponse {
 	}
 }
 
+func CreateTransactionWithReceiptResponse(transaction Transaction, receipt *Receipt) TransactionResponse {
+	return TransactionResponse{
+		Transaction: transaction,
+		Receipt:     receipt,
+	}
+}
+
 type TransactionsResponse struct {
 	Transactions []Transaction `json:"transactions"`
 	TotalCount   uint32        `json:"totalCount"`
@@ -61,15 +104,27 @@ func CreateTransactionsResponse(transactions []Transaction, totalCount uint32) T
 }
 
 type TransactionRequest struct {
-	Nonce      string `json:"nonce"`
-	From       string `json:"from"`
-	To         string `json:"to"`
-	Value      string `json:"value"`
-	Data       string `json:"data"`
-	SignerX    string `json:"signerX"`
-	SignerY    string `json:"signerY"`
-	SignatureR string `json:"signatureR"`
-	SignatureS string `json:"signatureS"`
+	Type    uint8  `json:"type"`
+	ChainID string `json:"chainId"`
+	Nonce   string `json:"nonce"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Value   string `json:"value"`
+	Fee     string `json:"fee"`
+	Data    string `json:"data"`
+
+	UnlockHeight int32 `json:"unlockHeight"`
+	UnlockTime   int64 `json:"unlockTime"`
+	ValidUntil   int32 `json:"validUntil"`
+
+	// Signature is the compact hex signature of the transaction hash, R, S and V.
+	Signature string `json:"signature"`
+
+	Cosignatures []CosignatureRequest `json:"cosignatures"`
+}
+
+type CosignatureRequest struct {
+	Signature string `json:"signature"`
 }
 
 type FaucetRequest struct {
@@ -1,20 +1,62 @@
 package restful
 
 import (
-	"bytes"
 	"encoding/hex"
+	"errors"
 	"github.com/barreleye-labs/barreleye/common"
 	"github.com/barreleye-labs/barreleye/common/util"
 	"github.com/barreleye-labs/barreleye/config"
+	"github.com/barreleye-labs/barreleye/core"
 	"github.com/barreleye-labs/barreleye/core/types"
+	"github.com/barreleye-labs/barreleye/core/vm"
 	"github.com/barreleye-labs/barreleye/restful/dto"
 	"github.com/labstack/echo/v4"
 	"math/big"
 	"net/http"
+	"sort"
 	"strconv"
 	"time"
 )
 
+func cosignatureDTOs(tx *types.Transaction) []dto.Cosignature {
+	cosignatures := []dto.Cosignature{}
+	for _, cosignature := range tx.Cosignatures {
+		signer := ""
+		if address, err := cosignature.Signature.RecoverAddress(tx.GetHash().ToSlice()); err == nil {
+			signer = address.Bech32()
+		}
+		cosignatures = append(cosignatures, dto.CreateCosignature(signer, signatureDTO(cosignature.Signature)))
+	}
+	return cosignatures
+}
+
+// signatureDTO returns the signature, empty for the genesis block, which is not signed.
+func signatureDTO(sig *types.Signature) dto.Signature {
+	if sig == nil {
+		return dto.CreateSignature("", "", 0, "")
+	}
+	return dto.CreateSignature(sig.R.Text(16), sig.S.Text(16), sig.V, sig.String())
+}
+
+// txSigner returns the address recovered from the signature of the transaction.
+func txSigner(tx *types.Transaction) string {
+	sender, err := tx.Sender()
+	if err != nil {
+		return ""
+	}
+	return sender.Bech32()
+}
+
+// formatCoins writes an amount of the base coin in whole coins, like "1.5 BRL".
+func formatCoins(amount common.Amount) string {
+	return amount.Format(config.Decimals) + " " + config.Symbol
+}
+
+// formatTokens writes an amount of the token with the decimals of the token, like "1.5 TKN".
+func formatTokens(amount common.Amount, token *types.Token) string {
+	return amount.Format(token.Decimals) + " " + token.Symbol
+}
+
 func (s *Server) requestSomeCoin(c echo.Context) error {
 	remainTime, ok := s.faucetLimit[c.RealIP()]
 	if ok {
@@ -28,22 +70,18 @@ func (s *Server) requestSomeCoin(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid payload "+err.Error()))
 	}
 
-	to, err := hex.DecodeString(util.Rm0x(payload.AccountAddress))
+	to, err := common.ParseChecksummedAddress(payload.AccountAddress)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid AccountAddress "+err.Error()))
-	}
-
-	if len(to) != 20 {
-		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid address length"))
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest(err.Error()))
 	}
 
-	toInfo, err := s.bc.ReadAccountByAddress(common.NewAddressFromBytes(to))
+	toInfo, err := s.bc.ReadAccountByAddress(to)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
 	}
 
-	if toInfo != nil && toInfo.Balance >= 10 {
-		return c.JSON(http.StatusBadRequest, ResponseBadRequest("The account already has sufficient balance of 10 Barrel or more."))
+	if toInfo != nil && !toInfo.Balance.Lt(common.CoinAmount(10, config.Decimals)) {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest("The account already has sufficient balance of 10 "+config.Symbol+" or more."))
 	}
 
 	accountNonce, err := s.bc.ReadAccountNonceByAddress(s.privateKey.PublicKey.Address())
@@ -59,8 +97,9 @@ func (s *Server) requestSomeCoin(c echo.Context) error {
 	tx := types.CreateTransaction(
 		nonce,
 		s.privateKey.PublicKey.Address(),
-		common.NewAddressFromBytes(to),
+		to,
 		config.FaucetAmount,
+		config.MinTxFee,
 		[]byte{171})
 
 	tx.Hash = tx.GetHash()
@@ -70,20 +109,26 @@ func (s *Server) requestSomeCoin(c echo.Context) error {
 
 	s.txChan <- tx
 
-	signerDTO := dto.CreateSigner(tx.Signer.Key.X.Text(16), tx.Signer.Key.Y.Text(16))
-	signatureDTO := dto.CreateSignature(tx.Signature.R.Text(16), tx.Signature.S.Text(16))
-
 	txDTO := dto.CreateTransaction(
+		uint8(tx.Type),
+		hex.EncodeToString(util.Uint64ToBytes(tx.ChainID)),
 		tx.Hash.String(),
 		hex.EncodeToString(util.Uint64ToBytes(tx.Nonce)),
 		-1,
 		-1,
-		tx.From.String(),
-		tx.To.String(),
-		hex.EncodeToString(util.Uint64ToBytes(tx.Value)),
+		tx.From.Bech32(),
+		tx.To.Bech32(),
+		tx.Value.Hex(),
+		tx.Fee.Hex(),
 		hex.EncodeToString(tx.Data),
-		signerDTO,
-		signatureDTO)
+		formatCoins(tx.Value),
+		formatCoins(tx.Fee),
+		tx.UnlockHeight,
+		tx.UnlockTime,
+		tx.ValidUntil,
+		txSigner(tx),
+		signatureDTO(tx.Signature),
+		cosignatureDTOs(tx))
 
 	s.faucetLimit[c.RealIP()] = time.Now().Unix() + config.FaucetDelayTime
 	return c.JSON(http.StatusOK, ResponseOk(dto.CreateTransactionResponse(txDTO)))
@@ -94,14 +139,26 @@ func (s *Server) getAccount(c echo.Context) error {
 
 	var result *types.Account = nil
 
-	bytes, err := hex.DecodeString(util.Rm0x(address))
+	accountAddress, err := common.ParseAddress(address)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid address"))
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest(err.Error()))
 	}
 
-	result, err = s.bc.ReadAccountByAddress(common.NewAddressFromBytes(bytes))
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+	if height := c.QueryParam("height"); height != "" {
+		h, err := strconv.ParseInt(height, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid height"))
+		}
+
+		result, err = s.bc.ReadAccountAtHeight(accountAddress, int32(h))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ResponseBadRequest(err.Error()))
+		}
+	} else {
+		result, err = s.bc.ReadAccountByAddress(accountAddress)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+		}
 	}
 
 	if result == nil {
@@ -109,9 +166,12 @@ func (s *Server) getAccount(c echo.Context) error {
 	}
 
 	return c.JSON(http.StatusOK, ResponseOk(dto.AccountResponse{Account: dto.Account{
-		Address: result.Address.String(),
-		Nonce:   hex.EncodeToString(util.Uint64ToBytes(result.Nonce)),
-		Balance: hex.EncodeToString(util.Uint64ToBytes(result.Balance)),
+		Address:          result.Address.Bech32(),
+		Nonce:            hex.EncodeToString(util.Uint64ToBytes(result.Nonce)),
+		Balance:          result.Balance.Hex(),
+		Locked:           result.Locked.Hex(),
+		FormattedBalance: formatCoins(result.Balance),
+		FormattedLocked:  formatCoins(result.Locked),
 	}}))
 }
 
@@ -130,16 +190,17 @@ func (s *Server) getLastBlock(c echo.Context) error {
 		transactions = append(transactions, result.Transactions[j].Hash.String())
 	}
 
-	signature := dto.CreateSignature(result.Signature.R.Text(16), result.Signature.S.Text(16))
+	signature := signatureDTO(result.Signature)
 
 	block := dto.CreateBlock(
 		result.Hash.String(),
 		result.Version,
 		result.DataHash.String(),
+		result.StateRoot.String(),
 		result.PrevBlockHash.String(),
 		result.Height,
 		result.Timestamp,
-		result.Signer.Address().String(),
+		result.Producer().Bech32(),
 		result.Extra,
 		signature,
 		uint32(len(result.Transactions)),
@@ -168,20 +229,29 @@ func (s *Server) getTxs(c echo.Context) error {
 
 	txs := []dto.Transaction{}
 	for i := 0; i < len(result); i++ {
-		signer := dto.CreateSigner(result[i].Signer.Key.X.Text(16), result[i].Signer.Key.Y.Text(16))
-		signature := dto.CreateSignature(result[i].Signature.R.Text(16), result[i].Signature.S.Text(16))
+		signer := txSigner(result[i])
+		signature := signatureDTO(result[i].Signature)
 
 		tx := dto.CreateTransaction(
+			uint8(result[i].Type),
+			hex.EncodeToString(util.Uint64ToBytes(result[i].ChainID)),
 			result[i].Hash.String(),
 			hex.EncodeToString(util.Uint64ToBytes(result[i].Nonce)),
 			result[i].BlockHeight,
 			result[i].Timestamp,
-			result[i].From.String(),
-			result[i].To.String(),
-			hex.EncodeToString(util.Uint64ToBytes(result[i].Value)),
+			result[i].From.Bech32(),
+			result[i].To.Bech32(),
+			result[i].Value.Hex(),
+			result[i].Fee.Hex(),
 			hex.EncodeToString(result[i].Data),
+			formatCoins(result[i].Value),
+			formatCoins(result[i].Fee),
+			result[i].UnlockHeight,
+			result[i].UnlockTime,
+			result[i].ValidUntil,
 			signer,
-			signature)
+			signature,
+			cosignatureDTOs(result[i]))
 
 		txs = append(txs, tx)
 	}
@@ -224,16 +294,17 @@ func (s *Server) getBlocks(c echo.Context) error {
 			transactions = append(transactions, result[i].Transactions[j].Hash.String())
 		}
 
-		signature := dto.CreateSignature(result[i].Signature.R.Text(16), result[i].Signature.S.Text(16))
+		signature := signatureDTO(result[i].Signature)
 
 		block := dto.CreateBlock(
 			result[i].Hash.String(),
 			result[i].Version,
 			result[i].DataHash.String(),
+			result[i].StateRoot.String(),
 			result[i].PrevBlockHash.String(),
 			result[i].Height,
 			result[i].Timestamp,
-			result[i].Signer.Address().String(),
+			result[i].Producer().Bech32(),
 			result[i].Extra,
 			signature,
 			uint32(len(result[i].Transactions)),
@@ -255,54 +326,61 @@ func (s *Server) postTx(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid payload "+err.Error()))
 	}
 
-	signer, err := types.GetPublicKey(payload.SignerX, payload.SignerY)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid signer "+err.Error()))
-	}
-
-	signature, err := types.GetSignature(payload.SignatureR, payload.SignatureS)
+	signature, err := types.ParseSignature(payload.Signature)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid signature "+err.Error()))
 	}
 
-	from, err := hex.DecodeString(util.Rm0x(payload.From))
+	from, err := common.ParseAddress(payload.From)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid from "+err.Error()))
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid from: "+err.Error()))
 	}
 
-	to, err := hex.DecodeString(util.Rm0x(payload.To))
+	to, err := common.ParseChecksummedAddress(payload.To)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid to "+err.Error()))
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid to: "+err.Error()))
 	}
 
-	account, err := s.bc.ReadAccountByAddress(common.NewAddressFromBytes(from))
+	account, err := s.bc.ReadAccountByAddress(from)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
 	}
 
-	if bytes.Equal(from, to) {
-		return c.JSON(http.StatusBadRequest, ResponseBadRequest("from and to must be different"))
-	}
-
 	nonce := uint64(0)
 	if account != nil {
 		nonce = account.Nonce
 	}
 
+	value, err := common.ParseAmount(payload.Value)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid value "+err.Error()))
+	}
+
+	fee, err := common.ParseAmount(payload.Fee)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid fee "+err.Error()))
+	}
+
+	if fee.Lt(config.MinTxFee) {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest("fee is lower than the minimum fee"))
+	}
+
+	if cost, overflow := value.Add(fee); account == nil || overflow || account.Balance.Lt(cost) {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest("insufficient balance"))
+	}
+
 	base := 10
-	if util.IsHex(payload.Value) {
+	if util.IsHex(payload.ChainID) {
 		base = 16
 	}
 
-	valueBigInt := new(big.Int)
-	valueBigInt, ok := valueBigInt.SetString(util.Rm0x(payload.Value), base)
-	if !ok {
-		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid value"))
+	chainID, ok := new(big.Int).SetString(util.Rm0x(payload.ChainID), base)
+	if !ok || !chainID.IsUint64() {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid chainId"))
 	}
 
-	value := valueBigInt.Uint64()
-	if value > account.Balance {
-		return c.JSON(http.StatusBadRequest, ResponseBadRequest("insufficient balance"))
+	if len(util.Rm0x(payload.Data))/2 > config.MaxTxDataSize {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest(common.ErrTxDataTooLarge.Error()))
 	}
 
 	data, err := hex.DecodeString(util.Rm0x(payload.Data))
@@ -312,21 +390,60 @@ func (s *Server) postTx(c echo.Context) error {
 
 	tx := types.CreateSignedTransaction(
 		nonce,
-		common.NewAddressFromBytes(from),
-		common.NewAddressFromBytes(to),
+		from,
+		to,
 		value,
+		fee,
 		data,
-		*signer,
 		signature)
+	tx.Type = types.TxType(payload.Type)
+	tx.ChainID = chainID.Uint64()
+	tx.UnlockHeight = payload.UnlockHeight
+	tx.UnlockTime = payload.UnlockTime
+	tx.ValidUntil = payload.ValidUntil
+
+	for _, cosignature := range payload.Cosignatures {
+		sig, err := types.ParseSignature(cosignature.Signature)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid cosignature "+err.Error()))
+		}
+
+		tx.Cosignatures = append(tx.Cosignatures, types.Cosignature{Signature: sig})
+	}
+
+	if err = tx.Verify(); err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest(err.Error()))
+	}
+
+	if err = core.ValidateTransaction(tx); err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest(err.Error()))
+	}
+
+	policy, err := s.bc.ReadMultisigPolicy(tx.From)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+	}
+
+	if err = core.AuthorizeSender(policy, tx); err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest(err.Error()))
+	}
+
+	lastBlockHeight, err := s.bc.ReadLastBlockHeight()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+	}
+
+	if tx.IsExpired(*lastBlockHeight + 1) {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest("tx has expired"))
+	}
 
 	tx.Hash = tx.GetHash()
 
 	s.txChan <- tx
 
-	signerDTO := dto.CreateSigner(payload.SignerX, payload.SignerY)
-	signatureDTO := dto.CreateSignature(payload.SignatureR, payload.SignatureS)
-
 	txDTO := dto.CreateTransaction(
+		uint8(tx.Type),
+		hex.EncodeToString(util.Uint64ToBytes(tx.ChainID)),
 		tx.Hash.String(),
 		hex.EncodeToString(util.Uint64ToBytes(tx.Nonce)),
 		-1,
@@ -334,9 +451,16 @@ func (s *Server) postTx(c echo.Context) error {
 		payload.From,
 		payload.To,
 		payload.Value,
+		payload.Fee,
 		payload.Data,
-		signerDTO,
-		signatureDTO)
+		formatCoins(tx.Value),
+		formatCoins(tx.Fee),
+		tx.UnlockHeight,
+		tx.UnlockTime,
+		tx.ValidUntil,
+		txSigner(tx),
+		signatureDTO(tx.Signature),
+		cosignatureDTOs(tx))
 
 	return c.JSON(http.StatusOK, ResponseOk(dto.CreateTransactionResponse(txDTO)))
 }
@@ -353,12 +477,17 @@ func (s *Server) getTx(c echo.Context) error {
 			return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
 		}
 	} else {
-		hash, err := hex.DecodeString(id)
+		b, err := hex.DecodeString(id)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid hash "+id))
+		}
+
+		hash, err := common.HashFromBytes(b)
 		if err != nil {
 			return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid hash "+id))
 		}
 
-		result, err = s.bc.ReadTxByHash(common.HashFromBytes(hash))
+		result, err = s.bc.ReadTxByHash(hash)
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
 		}
@@ -368,22 +497,135 @@ func (s *Server) getTx(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ResponseNotFound("not found transaction"+id))
 	}
 
-	signer := dto.CreateSigner(result.Signer.Key.X.Text(16), result.Signer.Key.Y.Text(16))
-	signature := dto.CreateSignature(result.Signature.R.Text(16), result.Signature.S.Text(16))
+	signer := txSigner(result)
+	signature := signatureDTO(result.Signature)
 
 	tx := dto.CreateTransaction(
+		uint8(result.Type),
+		hex.EncodeToString(util.Uint64ToBytes(result.ChainID)),
 		result.Hash.String(),
 		hex.EncodeToString(util.Uint64ToBytes(result.Nonce)),
 		result.BlockHeight,
 		result.Timestamp,
-		result.From.String(),
-		result.To.String(),
-		hex.EncodeToString(util.Uint64ToBytes(result.Value)),
+		result.From.Bech32(),
+		result.To.Bech32(),
+		result.Value.Hex(),
+		result.Fee.Hex(),
 		hex.EncodeToString(result.Data),
+		formatCoins(result.Value),
+		formatCoins(result.Fee),
+		result.UnlockHeight,
+		result.UnlockTime,
+		result.ValidUntil,
 		signer,
-		signature)
+		signature,
+		cosignatureDTOs(result))
+
+	receipt, err := s.bc.ReadReceiptByTxHash(result.GetHash())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+	}
+
+	if receipt == nil {
+		return c.JSON(http.StatusOK, ResponseOk(dto.CreateTransactionResponse(tx)))
+	}
+
+	balanceChanges := []dto.BalanceChange{}
+	for _, change := range receipt.BalanceChanges {
+		balanceChanges = append(balanceChanges, dto.CreateBalanceChange(
+			change.Address.Bech32(),
+			change.Before.Hex(),
+			change.After.Hex(),
+			formatCoins(change.Before),
+			formatCoins(change.After)))
+	}
+
+	contractAddress := ""
+	if !receipt.ContractAddress.Equal(common.Address{}) {
+		contractAddress = receipt.ContractAddress.Bech32()
+	}
+
+	receiptDTO := dto.CreateReceipt(
+		receipt.Status.String(),
+		uint16(receipt.ErrorCode),
+		receipt.Error,
+		receipt.BlockHash.String(),
+		receipt.BlockHeight,
+		receipt.Index,
+		balanceChanges,
+		hex.EncodeToString(util.Uint64ToBytes(receipt.GasUsed)),
+		contractAddress)
+
+	return c.JSON(http.StatusOK, ResponseOk(dto.CreateTransactionWithReceiptResponse(tx, receiptDTO)))
+}
+
+func (s *Server) getTxProof(c echo.Context) error {
+	id := c.Param("id")
+
+	var result *types.Transaction = nil
+
+	number, err := strconv.Atoi(id)
+	if err == nil {
+		result, err = s.bc.ReadTxByNumber(uint32(number))
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+		}
+	} else {
+		b, err := hex.DecodeString(id)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid hash "+id))
+		}
+
+		hash, err := common.HashFromBytes(b)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid hash "+id))
+		}
+
+		result, err = s.bc.ReadTxByHash(hash)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+		}
+	}
+
+	if result == nil {
+		return c.JSON(http.StatusNotFound, ResponseNotFound("not found transaction "+id))
+	}
+
+	block, err := s.bc.ReadBlockByHeight(result.BlockHeight)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+	}
 
-	return c.JSON(http.StatusOK, ResponseOk(dto.CreateTransactionResponse(tx)))
+	if block == nil {
+		return c.JSON(http.StatusNotFound, ResponseNotFound("not found block of transaction "+id))
+	}
+
+	proof, err := types.NewTxProof(block, result.Hash)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseNotFound(err.Error()))
+	}
+
+	if !types.VerifyTxProof(block.Header, result.Hash, proof) {
+		return c.JSON(http.StatusInternalServerError, ResponseServerError("proof does not match the data hash of block "+block.Hash.String()))
+	}
+
+	path := []dto.ProofNode{}
+	for _, node := range proof.Path {
+		position := "right"
+		if node.Left {
+			position = "left"
+		}
+		path = append(path, dto.CreateProofNode(node.Hash.String(), position))
+	}
+
+	txProof := dto.CreateTxProof(
+		result.Hash.String(),
+		block.Hash.String(),
+		block.Height,
+		block.DataHash.String(),
+		path)
+
+	return c.JSON(http.StatusOK, ResponseOk(dto.CreateTxProofResponse(txProof)))
 }
 
 func (s *Server) getBlock(c echo.Context) error {
@@ -398,12 +640,17 @@ func (s *Server) getBlock(c echo.Context) error {
 			return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
 		}
 	} else {
-		hash, err := hex.DecodeString(id)
+		b, err := hex.DecodeString(id)
 		if err != nil {
 			return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid hash "+id))
 		}
 
-		result, err = s.bc.ReadBlockByHash(common.HashFromBytes(hash))
+		hash, err := common.HashFromBytes(b)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid hash "+id))
+		}
+
+		result, err = s.bc.ReadBlockByHash(hash)
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
 		}
@@ -418,19 +665,276 @@ func (s *Server) getBlock(c echo.Context) error {
 		transactions = append(transactions, result.Transactions[j].Hash.String())
 	}
 
-	signature := dto.CreateSignature(result.Signature.R.Text(16), result.Signature.S.Text(16))
+	signature := signatureDTO(result.Signature)
 
 	block := dto.CreateBlock(
 		result.Hash.String(),
 		result.Version,
 		result.DataHash.String(),
+		result.StateRoot.String(),
 		result.PrevBlockHash.String(),
 		result.Height,
 		result.Timestamp,
-		result.Signer.Address().String(),
+		result.Producer().Bech32(),
 		result.Extra,
 		signature,
 		uint32(len(result.Transactions)),
 		transactions)
 	return c.JSON(http.StatusOK, ResponseOk(dto.CreateBlockResponse(block)))
 }
+
+func (s *Server) getChain(c echo.Context) error {
+	lastBlockHeight, err := s.bc.ReadLastBlockHeight()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+	}
+
+	if lastBlockHeight == nil {
+		return c.JSON(http.StatusNotFound, ResponseNotFound("not found last block"))
+	}
+
+	genesisHash, err := s.bc.ReadBlockHashByHeight(0)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+	}
+
+	if genesisHash == nil {
+		return c.JSON(http.StatusNotFound, ResponseNotFound("not found genesis block"))
+	}
+
+	chainDTO := dto.CreateChain(
+		hex.EncodeToString(util.Uint64ToBytes(config.ChainID)),
+		genesisHash.String(),
+		*lastBlockHeight,
+		config.Symbol,
+		config.Decimals)
+
+	return c.JSON(http.StatusOK, ResponseOk(dto.CreateChainResponse(chainDTO)))
+}
+
+// getForks returns the scheduled protocol upgrades, whether they are active for the
+// next block and the version of the next block.
+func (s *Server) getForks(c echo.Context) error {
+	lastBlockHeight, err := s.bc.ReadLastBlockHeight()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+	}
+
+	if lastBlockHeight == nil {
+		return c.JSON(http.StatusNotFound, ResponseNotFound("not found last block"))
+	}
+
+	nextHeight := *lastBlockHeight + 1
+	forks := []dto.Fork{}
+	for _, fork := range config.ForkSchedule() {
+		forks = append(forks, dto.CreateFork(fork.Name, fork.Height, config.IsForkActive(fork.Name, nextHeight)))
+	}
+
+	return c.JSON(http.StatusOK, ResponseOk(dto.CreateForksResponse(forks, config.BlockVersion(nextHeight), *lastBlockHeight)))
+}
+
+func (s *Server) getSupply(c echo.Context) error {
+	lastBlockHeight, err := s.bc.ReadLastBlockHeight()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+	}
+
+	if lastBlockHeight == nil {
+		return c.JSON(http.StatusNotFound, ResponseNotFound("not found last block"))
+	}
+
+	supply, err := s.bc.ReadTotalSupply()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+	}
+
+	reward := core.MintedReward(*lastBlockHeight+1, supply)
+
+	supplyDTO := dto.CreateSupply(
+		supply.Hex(),
+		config.MaxSupply.Hex(),
+		reward.Hex(),
+		*lastBlockHeight,
+		formatCoins(supply),
+		formatCoins(config.MaxSupply),
+		formatCoins(reward))
+
+	return c.JSON(http.StatusOK, ResponseOk(dto.CreateSupplyResponse(supplyDTO)))
+}
+
+func (s *Server) getToken(c echo.Context) error {
+	symbol := c.Param("symbol")
+	if err := types.ValidateTokenSymbol(symbol); err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid symbol "+err.Error()))
+	}
+
+	result, err := s.bc.ReadToken(symbol)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+	}
+
+	if result == nil {
+		return c.JSON(http.StatusNotFound, ResponseNotFound("not found token "+symbol))
+	}
+
+	token := dto.CreateToken(
+		result.Symbol,
+		result.Name,
+		result.Owner.Bech32(),
+		result.Supply.Hex(),
+		result.Decimals,
+		result.Mintable,
+		formatTokens(result.Supply, result))
+
+	return c.JSON(http.StatusOK, ResponseOk(dto.CreateTokenResponse(token)))
+}
+
+func (s *Server) getAccountTokens(c echo.Context) error {
+	address := c.Param("address")
+
+	accountAddress, err := common.ParseAddress(address)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest(err.Error()))
+	}
+
+	result, err := s.bc.ReadTokenBalances(accountAddress)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+	}
+
+	symbols := []string{}
+	for symbol := range result {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	balances := []dto.TokenBalance{}
+	for _, symbol := range symbols {
+		token, err := s.bc.ReadToken(symbol)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+		}
+
+		formatted := ""
+		if token != nil {
+			formatted = formatTokens(result[symbol], token)
+		}
+		balances = append(balances, dto.CreateTokenBalance(symbol, result[symbol].Hex(), formatted))
+	}
+
+	return c.JSON(http.StatusOK, ResponseOk(dto.CreateTokenBalancesResponse(accountAddress.Bech32(), balances)))
+}
+
+func (s *Server) getMultisig(c echo.Context) error {
+	address := c.Param("address")
+
+	accountAddress, err := common.ParseAddress(address)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest(err.Error()))
+	}
+
+	result, err := s.bc.ReadMultisigPolicy(accountAddress)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+	}
+
+	if result == nil {
+		return c.JSON(http.StatusNotFound, ResponseNotFound("not found multisig account"))
+	}
+
+	signers := []string{}
+	for _, signer := range result.Signers {
+		signers = append(signers, signer.Bech32())
+	}
+
+	multisig := dto.CreateMultisig(result.Address().Bech32(), result.Threshold, signers)
+	return c.JSON(http.StatusOK, ResponseOk(dto.CreateMultisigResponse(multisig)))
+}
+
+func (s *Server) getAccountLocks(c echo.Context) error {
+	address := c.Param("address")
+
+	accountAddress, err := common.ParseAddress(address)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest(err.Error()))
+	}
+
+	hashes, result, err := s.bc.ReadLocks(accountAddress)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+	}
+
+	locks := []dto.Lock{}
+	for i, lock := range result {
+		locks = append(locks, dto.CreateLock(
+			hashes[i].String(),
+			lock.Amount.Hex(),
+			lock.UnlockHeight,
+			lock.UnlockTime,
+			formatCoins(lock.Amount)))
+	}
+
+	return c.JSON(http.StatusOK, ResponseOk(dto.CreateLocksResponse(accountAddress.Bech32(), locks)))
+}
+
+func (s *Server) getContract(c echo.Context) error {
+	address := c.Param("address")
+
+	accountAddress, err := common.ParseAddress(address)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest(err.Error()))
+	}
+
+	code, err := s.bc.ReadCode(accountAddress)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+	}
+
+	if code == nil {
+		return c.JSON(http.StatusNotFound, ResponseNotFound("contract not found"))
+	}
+
+	return c.JSON(http.StatusOK, ResponseOk(dto.CreateContractResponse(accountAddress.Bech32(), hex.EncodeToString(code))))
+}
+
+func (s *Server) callContract(c echo.Context) error {
+	address := c.Param("address")
+
+	accountAddress, err := common.ParseAddress(address)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest(err.Error()))
+	}
+
+	payload := &dto.CallContractRequest{}
+	if err = c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid payload "+err.Error()))
+	}
+
+	caller := common.Address{}
+	if payload.From != "" {
+		if caller, err = common.ParseAddress(payload.From); err != nil {
+			return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid from: "+err.Error()))
+		}
+	}
+
+	input, err := hex.DecodeString(util.Rm0x(payload.Data))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest("invalid data "+err.Error()))
+	}
+
+	result, err := s.bc.CallContract(accountAddress, caller, input)
+	if err != nil {
+		if errors.Is(err, vm.ErrState) {
+			return c.JSON(http.StatusInternalServerError, ResponseServerError(err.Error()))
+		}
+		return c.JSON(http.StatusBadRequest, ResponseBadRequest("call failed: "+err.Error()))
+	}
+
+	if result == nil {
+		return c.JSON(http.StatusNotFound, ResponseNotFound("contract not found"))
+	}
+
+	return c.JSON(http.StatusOK, ResponseOk(dto.CreateCallContractResponse(
+		hex.EncodeToString(result.Output),
+		hex.EncodeToString(util.Uint64ToBytes(result.GasUsed)))))
+}
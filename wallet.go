@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/barreleye-labs/barreleye/keystore"
+	"github.com/barreleye-labs/barreleye/wallet"
+	"os"
+)
+
+const walletUsage = `usage: barreleye wallet <command> [flags]
+
+commands:
+  new       create a mnemonic phrase and print it with the addresses of its accounts
+  recover   print the addresses of the accounts of an existing mnemonic phrase
+
+Add -import to store the keys of the printed accounts in the keystore.`
+
+// runWalletCommand runs the wallet commands that derive account keys from a mnemonic.
+func runWalletCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New(walletUsage)
+	}
+
+	fs := flag.NewFlagSet("wallet "+args[0], flag.ExitOnError)
+	words := fs.Int("words", 24, "number of words of a new mnemonic, 12, 15, 18, 21 or 24")
+	mnemonicFile := fs.String("mnemonic", "", "file with the mnemonic to recover, prompted for if empty")
+	passphraseFile := fs.String("passphrase", "", "file with the optional BIP39 passphrase of the mnemonic")
+	index := fs.Uint("index", 0, "index of the first account")
+	count := fs.Uint("count", 1, "number of accounts")
+	importKeys := fs.Bool("import", false, "store the keys of the accounts in the keystore")
+	dir := fs.String("keystore", "keys", "keystore directory")
+	passwordFile := fs.String("password", "", "file with the keystore password, prompted for if empty")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	var mnemonic string
+	var err error
+	switch args[0] {
+	case "new":
+		if mnemonic, err = wallet.NewMnemonic(*words); err != nil {
+			return err
+		}
+
+	case "recover":
+		if *mnemonicFile != "" {
+			mnemonic, err = readFirstLine(*mnemonicFile)
+		} else {
+			mnemonic, err = promptSecret("Mnemonic: ")
+		}
+		if err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unknown wallet command %s\n\n%s", args[0], walletUsage)
+	}
+
+	passphrase := ""
+	if *passphraseFile != "" {
+		if passphrase, err = readFirstLine(*passphraseFile); err != nil {
+			return err
+		}
+	}
+
+	w, err := wallet.NewWallet(mnemonic, passphrase)
+	if err != nil {
+		return err
+	}
+
+	if args[0] == "new" {
+		fmt.Fprintln(os.Stderr, "Write the mnemonic down and keep it safe, it restores all accounts of the wallet:")
+		fmt.Println(w.Mnemonic())
+	}
+
+	password := ""
+	if *importKeys {
+		if password, err = readPassword(*passwordFile, true); err != nil {
+			return err
+		}
+	}
+
+	for i := uint32(*index); i < uint32(*index+*count); i++ {
+		key, err := w.Account(i)
+		if err != nil {
+			return err
+		}
+
+		if !*importKeys {
+			fmt.Printf("%s %s\n", wallet.AccountPath(i), key.PublicKey.Address().Bech32())
+			continue
+		}
+
+		path, err := keystore.Store(*dir, key, password, keystore.StandardScryptN, keystore.StandardScryptP)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s %s %s\n", wallet.AccountPath(i), key.PublicKey.Address().Bech32(), path)
+	}
+	return nil
+}
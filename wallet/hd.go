@@ -0,0 +1,172 @@
+package wallet
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/barreleye-labs/barreleye/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// HardenedOffset is added to a child index to derive a hardened child.
+	HardenedOffset uint32 = 0x80000000
+
+	// CoinType is the BIP44 coin type of barreleye keys.
+	CoinType uint32 = 7000
+)
+
+var masterKeySecret = []byte("Bitcoin seed")
+
+// ErrInvalidChild is returned for the rare child indexes that do not give a valid key,
+// the next index should be used instead.
+var ErrInvalidChild = errors.New("child index gives an invalid key")
+
+// ExtendedKey is a BIP32 extended private key.
+type ExtendedKey struct {
+	key       []byte
+	chainCode []byte
+	depth     uint8
+}
+
+// NewMasterKey derives the BIP32 master key of a seed.
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("seed must be 16 to 64 bytes, not %d", len(seed))
+	}
+
+	mac := hmac.New(sha512.New, masterKeySecret)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	if !validScalar(new(big.Int).SetBytes(sum[:32])) {
+		return nil, fmt.Errorf("seed gives an invalid master key")
+	}
+	return &ExtendedKey{key: sum[:32], chainCode: sum[32:]}, nil
+}
+
+// Child derives the child key at the index. Indexes from HardenedOffset on derive
+// hardened children.
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	if k.depth == 255 {
+		return nil, fmt.Errorf("key is at the maximum depth")
+	}
+
+	data := make([]byte, 0, 37)
+	if index >= HardenedOffset {
+		data = append(data, 0)
+		data = append(data, k.key...)
+	} else {
+		privateKey, err := k.PrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, crypto.CompressPubkey(privateKey.PublicKey.Key)...)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	tweak := new(big.Int).SetBytes(sum[:32])
+	if tweak.Cmp(secp256k1.S256().N) >= 0 {
+		return nil, ErrInvalidChild
+	}
+
+	childKey := tweak.Add(tweak, new(big.Int).SetBytes(k.key))
+	childKey.Mod(childKey, secp256k1.S256().N)
+	if !validScalar(childKey) {
+		return nil, ErrInvalidChild
+	}
+
+	return &ExtendedKey{
+		key:       childKey.FillBytes(make([]byte, 32)),
+		chainCode: sum[32:],
+		depth:     k.depth + 1,
+	}, nil
+}
+
+// Derive derives the key at the path below this key.
+func (k *ExtendedKey) Derive(path DerivationPath) (*ExtendedKey, error) {
+	key := k
+	for _, index := range path {
+		child, err := key.Child(index)
+		if err != nil {
+			return nil, err
+		}
+		key = child
+	}
+	return key, nil
+}
+
+// PrivateKey returns the secp256k1 private key of the extended key.
+func (k *ExtendedKey) PrivateKey() (*types.PrivateKey, error) {
+	return types.PrivateKeyFromBytes(k.key)
+}
+
+// ChainCode returns the chain code of the extended key.
+func (k *ExtendedKey) ChainCode() []byte {
+	return append([]byte{}, k.chainCode...)
+}
+
+// Depth returns the number of derivations from the master key.
+func (k *ExtendedKey) Depth() uint8 {
+	return k.depth
+}
+
+func validScalar(s *big.Int) bool {
+	return s.Sign() > 0 && s.Cmp(secp256k1.S256().N) < 0
+}
+
+// DerivationPath is a BIP32 path as child indexes from the master key.
+type DerivationPath []uint32
+
+// AccountPath returns the BIP44 path m/44'/7000'/0'/0/index of the account at the index.
+func AccountPath(index uint32) DerivationPath {
+	return DerivationPath{44 + HardenedOffset, CoinType + HardenedOffset, HardenedOffset, 0, index}
+}
+
+// ParseDerivationPath parses a path such as m/44'/7000'/0'/0/0. A ' or h after an index
+// marks a hardened child.
+func ParseDerivationPath(s string) (DerivationPath, error) {
+	parts := strings.Split(strings.TrimSpace(s), "/")
+	if parts[0] != "m" {
+		return nil, fmt.Errorf("derivation path %q must start with m", s)
+	}
+
+	path := DerivationPath{}
+	for _, part := range parts[1:] {
+		offset := uint32(0)
+		if strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h") {
+			offset = HardenedOffset
+			part = part[:len(part)-1]
+		}
+
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || uint32(index) >= HardenedOffset {
+			return nil, fmt.Errorf("invalid index %q in derivation path %q", part, s)
+		}
+		path = append(path, uint32(index)+offset)
+	}
+	return path, nil
+}
+
+func (p DerivationPath) String() string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, index := range p {
+		if index >= HardenedOffset {
+			fmt.Fprintf(&b, "/%d'", index-HardenedOffset)
+		} else {
+			fmt.Fprintf(&b, "/%d", index)
+		}
+	}
+	return b.String()
+}
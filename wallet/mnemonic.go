@@ -0,0 +1,120 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"fmt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+	"math/big"
+	"strings"
+)
+
+const (
+	seedIterations = 2048
+	seedLength     = 64
+	bitsPerWord    = 11
+)
+
+// english.txt is the English word list of BIP39.
+//
+//go:embed english.txt
+var englishWords string
+
+var (
+	wordList  = strings.Split(strings.TrimSpace(englishWords), "\n")
+	wordIndex = map[string]int{}
+)
+
+func init() {
+	for i, word := range wordList {
+		wordIndex[word] = i
+	}
+}
+
+// NewMnemonic returns a BIP39 mnemonic of the given number of words, which is 12, 15,
+// 18, 21 or 24.
+func NewMnemonic(words int) (string, error) {
+	if words < 12 || words > 24 || words%3 != 0 {
+		return "", fmt.Errorf("a mnemonic has 12, 15, 18, 21 or 24 words, not %d", words)
+	}
+
+	entropy := make([]byte, words*4/3)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", err
+	}
+	return MnemonicFromEntropy(entropy)
+}
+
+// MnemonicFromEntropy encodes 16 to 32 bytes of entropy as a BIP39 mnemonic.
+func MnemonicFromEntropy(entropy []byte) (string, error) {
+	if len(entropy) < 16 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return "", fmt.Errorf("entropy must be 16 to 32 bytes in steps of 4, not %d", len(entropy))
+	}
+
+	checksumBits := len(entropy) / 4
+	checksum := sha256.Sum256(entropy)
+
+	// entropy || first checksumBits of sha256(entropy), split into 11-bit word indexes
+	bits := new(big.Int).SetBytes(entropy)
+	bits.Lsh(bits, uint(checksumBits))
+	bits.Or(bits, big.NewInt(int64(checksum[0]>>(8-checksumBits))))
+
+	words := make([]string, (len(entropy)*8+checksumBits)/bitsPerWord)
+	mask := big.NewInt(1<<bitsPerWord - 1)
+	for i := len(words) - 1; i >= 0; i-- {
+		index := new(big.Int).And(bits, mask)
+		words[i] = wordList[index.Int64()]
+		bits.Rsh(bits, bitsPerWord)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy decodes a BIP39 mnemonic and checks its word list and checksum.
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, fmt.Errorf("a mnemonic has 12, 15, 18, 21 or 24 words, not %d", len(words))
+	}
+
+	bits := new(big.Int)
+	for i, word := range words {
+		index, ok := wordIndex[strings.ToLower(word)]
+		if !ok {
+			return nil, fmt.Errorf("word %d of the mnemonic, %q, is not in the word list", i+1, word)
+		}
+		bits.Lsh(bits, bitsPerWord)
+		bits.Or(bits, big.NewInt(int64(index)))
+	}
+
+	checksumBits := len(words) / 3
+	checksum := new(big.Int).And(bits, big.NewInt(1<<checksumBits-1))
+	bits.Rsh(bits, uint(checksumBits))
+
+	entropy := bits.FillBytes(make([]byte, checksumBits*4))
+	hash := sha256.Sum256(entropy)
+	if checksum.Int64() != int64(hash[0]>>(8-checksumBits)) {
+		return nil, fmt.Errorf("invalid mnemonic checksum, check the words and their order")
+	}
+	return entropy, nil
+}
+
+// ValidateMnemonic returns an error if the mnemonic is not a valid BIP39 mnemonic.
+func ValidateMnemonic(mnemonic string) error {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err
+}
+
+// MnemonicToSeed checks the mnemonic and derives the 64-byte BIP39 seed of the mnemonic
+// and passphrase.
+func MnemonicToSeed(mnemonic string, passphrase string) ([]byte, error) {
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+
+	normalized := norm.NFKD.String(strings.Join(strings.Fields(strings.ToLower(mnemonic)), " "))
+	salt := norm.NFKD.String("mnemonic" + passphrase)
+	return pbkdf2.Key([]byte(normalized), []byte(salt), seedIterations, seedLength, sha512.New), nil
+}
@@ -0,0 +1,55 @@
+package wallet
+
+import (
+	"github.com/barreleye-labs/barreleye/common"
+	"github.com/barreleye-labs/barreleye/core/types"
+)
+
+// Wallet derives the keys of its accounts from a BIP39 mnemonic, so that the mnemonic
+// is the only backup the accounts need.
+type Wallet struct {
+	mnemonic string
+	master   *ExtendedKey
+}
+
+// NewWallet creates a wallet of a mnemonic and an optional passphrase. Another
+// passphrase gives other accounts.
+func NewWallet(mnemonic string, passphrase string) (*Wallet, error) {
+	seed, err := MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	return &Wallet{mnemonic: mnemonic, master: master}, nil
+}
+
+func (w *Wallet) Mnemonic() string {
+	return w.mnemonic
+}
+
+// Derive returns the private key at the path.
+func (w *Wallet) Derive(path DerivationPath) (*types.PrivateKey, error) {
+	key, err := w.master.Derive(path)
+	if err != nil {
+		return nil, err
+	}
+	return key.PrivateKey()
+}
+
+// Account returns the private key of the account at the index, derived at AccountPath.
+func (w *Wallet) Account(index uint32) (*types.PrivateKey, error) {
+	return w.Derive(AccountPath(index))
+}
+
+// Address returns the address of the account at the index.
+func (w *Wallet) Address(index uint32) (common.Address, error) {
+	key, err := w.Account(index)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return key.PublicKey.Address(), nil
+}
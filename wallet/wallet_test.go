@@ -0,0 +1,155 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// BIP39 test vectors with the passphrase TREZOR.
+var mnemonicVectors = []struct {
+	entropy  string
+	mnemonic string
+	seed     string
+}{
+	{
+		"00000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+	},
+	{
+		"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+	},
+	{
+		"9e885d952ad362caeb4efe34a8e91bd2",
+		"ozone drill grab fiber curtain grace pudding thank cruise elder eight picnic",
+		"274ddc525802f7c828d8ef7ddbcdc5304e87ac3535913611fbbfa986d0c9e5476c91689f9c8a54fd55bd38606aa6a8595ad213d4c9c9f9aca3fb217069a41028",
+	},
+	{
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote",
+		"dd48c104698c30cfe2b6142103248622fb7bb0ff692eebb00089b32d22484e1613912f0a5b694407be899ffd31ed3992c456cdf60f5d4564b8ba3f05a69890ad",
+	},
+}
+
+func TestMnemonicVectors(t *testing.T) {
+	assert.Equal(t, 2048, len(wordList))
+
+	for _, v := range mnemonicVectors {
+		entropy, _ := hex.DecodeString(v.entropy)
+
+		mnemonic, err := MnemonicFromEntropy(entropy)
+		assert.Nil(t, err)
+		assert.Equal(t, v.mnemonic, mnemonic)
+
+		decoded, err := MnemonicToEntropy(mnemonic)
+		assert.Nil(t, err)
+		assert.Equal(t, entropy, decoded)
+
+		seed, err := MnemonicToSeed(mnemonic, "TREZOR")
+		assert.Nil(t, err)
+		assert.Equal(t, v.seed, hex.EncodeToString(seed))
+	}
+}
+
+func TestInvalidMnemonic(t *testing.T) {
+	// the last word carries the checksum
+	assert.NotNil(t, ValidateMnemonic(strings.Repeat("abandon ", 12)))
+	assert.NotNil(t, ValidateMnemonic(strings.Repeat("abandon ", 11)+"abut"))
+	assert.NotNil(t, ValidateMnemonic(strings.Repeat("abandon ", 10)+"about"))
+	assert.Nil(t, ValidateMnemonic("  Abandon "+strings.Repeat("abandon ", 10)+"about\n"))
+
+	mnemonic, err := NewMnemonic(24)
+	assert.Nil(t, err)
+	assert.Equal(t, 24, len(strings.Fields(mnemonic)))
+	assert.Nil(t, ValidateMnemonic(mnemonic))
+
+	_, err = NewMnemonic(13)
+	assert.NotNil(t, err)
+}
+
+// BIP32 test vector 1.
+func TestExtendedKeyVectors(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(seed)
+	assert.Nil(t, err)
+	assert.Equal(t, "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35", hex.EncodeToString(master.key))
+	assert.Equal(t, "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508", hex.EncodeToString(master.ChainCode()))
+
+	vectors := []struct {
+		path      string
+		key       string
+		chainCode string
+	}{
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea", "47fdacbd0f1097043b78c63c20c34ef4ed9a111d980047ad16282c7ae6236141"},
+		{"m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368", "2a7857631386ba23dacac34180dd1983734e444fdbf774041578e9b6adb37c19"},
+		{"m/0'/1/2'", "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca", "04466b9cc8e161e966409ca52986c584f07e9dc81f735db683c3ff6ec7b1503f"},
+		{"m/0'/1/2'/2", "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4", "cfb71883f01676f587d023cc53a35bc7f88f724b1f8c2892ac1275ac822a3edd"},
+		{"m/0h/1/2h/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8", "c783e67b921d2beb8f6b389cc646d7263b4145701dadd2161548a8b078e65e9e"},
+	}
+
+	for _, v := range vectors {
+		path, err := ParseDerivationPath(v.path)
+		assert.Nil(t, err)
+
+		key, err := master.Derive(path)
+		assert.Nil(t, err)
+		assert.Equal(t, v.key, hex.EncodeToString(key.key), v.path)
+		assert.Equal(t, v.chainCode, hex.EncodeToString(key.ChainCode()), v.path)
+		assert.Equal(t, uint8(len(path)), key.Depth())
+
+		privateKey, err := key.PrivateKey()
+		assert.Nil(t, err)
+		assert.Equal(t, v.key, hex.EncodeToString(privateKey.Bytes()))
+	}
+}
+
+func TestDerivationPath(t *testing.T) {
+	path, err := ParseDerivationPath("m/44'/7000'/0'/0/5")
+	assert.Nil(t, err)
+	assert.Equal(t, AccountPath(5), path)
+	assert.Equal(t, "m/44'/7000'/0'/0/5", path.String())
+
+	for _, invalid := range []string{"", "44'/0", "m/x", "m/2147483648", "m/-1", "m//1"} {
+		_, err = ParseDerivationPath(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestWalletAccounts(t *testing.T) {
+	mnemonic := mnemonicVectors[0].mnemonic
+
+	w, err := NewWallet(mnemonic, "")
+	assert.Nil(t, err)
+
+	first, err := w.Account(0)
+	assert.Nil(t, err)
+	assert.Equal(t, "910aa0d972465e65c86c0b75bf6d2fbd5af2e397a16029f738c996f3ce5ca186", hex.EncodeToString(first.Bytes()))
+
+	address, err := w.Address(0)
+	assert.Nil(t, err)
+	assert.Equal(t, "7328332cb1e37d131d138d0776dcf21eec1791ec", address.String())
+
+	// the same mnemonic always gives the same accounts, another passphrase other ones
+	again, err := NewWallet(mnemonic, "")
+	assert.Nil(t, err)
+	againAddress, err := again.Address(0)
+	assert.Nil(t, err)
+	assert.Equal(t, address, againAddress)
+
+	second, err := w.Address(1)
+	assert.Nil(t, err)
+	assert.NotEqual(t, address, second)
+
+	protected, err := NewWallet(mnemonic, "TREZOR")
+	assert.Nil(t, err)
+	protectedAddress, err := protected.Address(0)
+	assert.Nil(t, err)
+	assert.NotEqual(t, address, protectedAddress)
+
+	_, err = NewWallet(strings.Repeat("abandon ", 12), "")
+	assert.NotNil(t, err)
+}
@@ -1,10 +1,12 @@
 name="youngmin"
-role="normal"
 port="4102"
 peers="localhost:4101"
 httpPort="9002"
-key=
+keyFile=
+passwordFile=
+hostKeystoreDir="/data/keystore"
+containerKeystoreDir="/barreleye/keys"
 hostDataDir="/data/youngmin"
 containerDataDir="/barreleye/barreldb/youngmin"
 
-docker run -d -it --name ${name} --net host -v ${hostDataDir}:${containerDataDir} kym6772/barreleye:1.0.0 /barreleye/bin/barreleye -name=${name} -role=${role} -port=${port} -peers=${peers} -http.port=${httpPort} -key=${key}
\ No newline at end of file
+docker run -d -it --name ${name} --net host -v ${hostDataDir}:${containerDataDir} -v ${hostKeystoreDir}:${containerKeystoreDir}:ro kym6772/barreleye:1.0.0 /barreleye/bin/barreleye -name=${name} -port=${port} -peers=${peers} -http.port=${httpPort} -keyfile=${containerKeystoreDir}/${keyFile} -password=${containerKeystoreDir}/${passwordFile} -genesis=/barreleye/genesis.json
\ No newline at end of file